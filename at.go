@@ -0,0 +1,58 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// atPrefix is a schedule prefix for one-shot runs, see AddAt.
+const atPrefix = "@at "
+
+// atSchedule is a one-shot schedule which fires once at given time.
+type atSchedule time.Time
+
+// Next returns schedule time if it is after t, otherwise zero time (never).
+func (s atSchedule) Next(t time.Time) time.Time {
+	if at := time.Time(s); t.Before(at) {
+		return at
+	}
+
+	return time.Time{}
+}
+
+// parseAt parses "@at 2025-07-01T09:00:00+03:00" spec.
+func parseAt(spec string) (atSchedule, bool, error) {
+	v, ok := strings.CutPrefix(spec, atPrefix)
+	if !ok {
+		return atSchedule{}, false, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(v))
+	return atSchedule(t), true, err
+}
+
+// AddAt adds func which runs once at t, e.g. to send a reminder. Job state shows t as next run
+// and becomes completed after the run. Schedule is "@at <RFC3339 time>", it can be passed to AddFunc as well.
+func (cm *Manager) AddAt(name string, t time.Time, fn Func, opts ...JobOption) {
+	cm.AddFunc(name, Schedule(atPrefix+t.Format(time.RFC3339)), fn, opts...)
+}
+
+// newAtFunc wraps scheduled one-shot job fn: it sets completed state and removes job from scheduler after the run.
+// Skipped run keeps skipped state with its reason.
+func (cm *Manager) newAtFunc(idx int, fn Func) Func {
+	return func(ctx context.Context) error {
+		err := fn(ctx)
+		if !errors.Is(err, ErrSkipped) {
+			cm.updateState(idx, stateCompleted, err)
+		}
+
+		cm.muState.RLock()
+		id := cm.jobs[idx].id
+		cm.muState.RUnlock()
+		cm.cron.Remove(id)
+
+		return err
+	}
+}
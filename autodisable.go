@@ -0,0 +1,174 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/robfig/cron/v3"
+)
+
+// DisableAfterFailures stops scheduling the job after n consecutive failures (skips are not counted):
+// job gets disabled state and is counted in app_cron_auto_disabled_total metric (with app label of WithMetrics).
+// Job stays runnable manually: successful run or Manager.Enable resumes scheduling.
+func DisableAfterFailures(n int) JobOption {
+	return func(j *job) {
+		j.disableAfter = n
+	}
+}
+
+// Enable resumes scheduling of the job disabled by DisableAfterFailures or Disable and resets its failures streak.
+func (cm *Manager) Enable(name string) error {
+	idx := cm.jobIndex(name)
+	if idx < 0 {
+		return ErrNotFound
+	}
+
+	cm.muState.Lock()
+	j := cm.jobs[idx]
+	if !j.autoDisabled {
+		cm.muState.Unlock()
+		return nil
+	}
+
+	j.autoDisabled, j.userDisabled = false, false
+	j.last.state, j.last.failures, j.last.reason, j.last.kind = stateIdle, 0, "", ""
+	cm.muState.Unlock()
+
+	cm.schedule(idx)
+	cm.notifyState(idx)
+	return nil
+}
+
+// Disable stops scheduling of the job until Enable, e.g. from UI. Job stays runnable manually.
+func (cm *Manager) Disable(name string) error {
+	idx := cm.jobIndex(name)
+	if idx < 0 {
+		return ErrNotFound
+	}
+
+	cm.muState.Lock()
+	j := cm.jobs[idx]
+	if !j.schedule.IsActive() || j.autoDisabled {
+		cm.muState.Unlock()
+		return nil
+	}
+
+	j.autoDisabled, j.userDisabled = true, true
+	j.last.state, j.last.reason, j.last.kind = stateDisabled, j.disabledReason(), SkipDisabled
+	id := j.id
+	cm.muState.Unlock()
+
+	cm.cron.Remove(id)
+	cm.notifyState(idx)
+	return nil
+}
+
+// disabledReason returns reason of disabled state.
+func (j *job) disabledReason() string {
+	if j.userDisabled {
+		return "disabled manually"
+	}
+	return "disabled after failures"
+}
+
+// Reschedule sets new active schedule for the job: it turns on the job with "disabled" or empty schedule
+// or replaces job schedule. Job disabled by DisableAfterFailures or Disable is enabled.
+func (cm *Manager) Reschedule(name string, schedule Schedule) error {
+	idx := cm.jobIndex(name)
+	if idx < 0 {
+		return ErrNotFound
+	}
+
+	j := cm.jobs[idx]
+	if !schedule.IsActive() {
+		return fmt.Errorf("%w: %q is not active schedule", ErrInvalidSchedule, schedule)
+	}
+
+	sch, spec, err := cm.parse(j.name, schedule)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSchedule, err)
+	}
+
+	cm.muState.Lock()
+	id := j.id
+	j.schedule, j.sched, j.spec, j.autoDisabled, j.userDisabled = schedule, sch, spec, false, false
+	if j.last.state == stateDisabled || j.last.state == stateUnscheduled {
+		j.last.state, j.last.reason, j.last.kind = stateIdle, "", ""
+	}
+	cm.muState.Unlock()
+
+	if cm.runCtx != nil {
+		cm.cron.Remove(id)
+		cm.schedule(idx)
+		cm.notifyState(idx)
+	}
+
+	return nil
+}
+
+// checkFailures disables job after too many consecutive failures or resumes it after successful run.
+func (cm *Manager) checkFailures(ctx context.Context, idx int, err error) {
+	j := cm.jobs[idx]
+	if j.disableAfter <= 0 {
+		return
+	}
+
+	cm.muState.Lock()
+	if j.sched == nil {
+		// manual-only job has nothing to stop or resume
+		cm.muState.Unlock()
+		return
+	}
+
+	disable := !j.autoDisabled && j.last.failures >= j.disableAfter
+	enable := j.autoDisabled && !j.userDisabled && err == nil
+	if disable {
+		j.autoDisabled = true
+		j.last.state, j.last.reason, j.last.kind = stateDisabled, j.disabledReason(), SkipDisabled
+	}
+	failures, id, app := j.last.failures, j.id, j.metricsApp
+	cm.muState.Unlock()
+
+	switch {
+	case disable:
+		cm.cron.Remove(id)
+		cm.notifyState(idx)
+		metricAutoDisabled().WithLabelValues(app, j.name).Inc()
+		if cm.logger != nil {
+			cm.logger.Error(ctx, "cron job disabled after failures", "job", j.name, "failures", failures, "err", err)
+		}
+	case enable:
+		_ = cm.Enable(j.name)
+	}
+}
+
+// schedule registers job in robfig/cron with Run context. It does nothing before Run, if paused by PauseAll,
+// for disabled job (see Disable) or for manual-only job without schedule.
+func (cm *Manager) schedule(idx int) {
+	if cm.runCtx == nil {
+		return
+	}
+
+	cm.muState.Lock()
+	defer cm.muState.Unlock()
+
+	j := cm.jobs[idx]
+	if cm.paused || j.sched == nil || j.autoDisabled {
+		return
+	}
+
+	schedFn, ctx := j.schedFn, cm.runCtx
+	run := func() { _ = schedFn(ctx) }
+	if cm.pool != nil {
+		run = func() { cm.enqueue(ctx, idx, schedFn) }
+	}
+	j.id = cm.cron.Schedule(j.sched, cron.FuncJob(run))
+}
+
+// isAutoDisabled checks if job is disabled by DisableAfterFailures.
+func (cm *Manager) isAutoDisabled(j *job) bool {
+	cm.muState.RLock()
+	defer cm.muState.RUnlock()
+
+	return j.autoDisabled
+}
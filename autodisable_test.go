@@ -0,0 +1,189 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDisableAfterFailures(t *testing.T) {
+	Convey("Test auto-disable after failures", t, func() {
+		clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 30, 0, time.UTC)}
+		lg := &testLogger{}
+		m := NewManager(WithClock(clock), WithManualTicker(), WithManagerLogger(lg))
+		m.Use(WithMetrics("test-disable"))
+		disabled := metricDelta("app_cron_auto_disabled_total", map[string]string{"app": "test-disable", "cron": "f1"})
+
+		var fail = true
+		var runs int
+		m.AddFunc("f1", "* * * * *", func(context.Context) error {
+			runs++
+			if fail {
+				return errors.New("failed")
+			}
+			return nil
+		}, DisableAfterFailures(2))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		for range 3 {
+			clock.Add(time.Minute)
+			_ = m.Tick(t.Context(), clock.Now())
+		}
+		So(runs, ShouldEqual, 2)
+
+		st := m.State()[0]
+		So(st.LastState, ShouldEqual, "disabled")
+		So(st.Failures, ShouldEqual, 2)
+		So(disabled(), ShouldEqual, 1)
+		So(st.LastErr.Error(), ShouldEqual, "failed")
+		msg, _ := lg.last()
+		So(msg, ShouldEqual, "cron job disabled after failures")
+
+		Convey("Test failed manual run keeps disabled state", func() {
+			So(m.ManualRun(t.Context(), "f1"), ShouldNotBeNil)
+			st := m.State()[0]
+			So(st.LastState, ShouldEqual, "disabled")
+			So(st.SkipReason, ShouldEqual, "disabled after failures")
+			So(st.Failures, ShouldEqual, 3)
+		})
+
+		Convey("Test successful manual run resumes scheduling", func() {
+			fail = false
+			So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+			So(m.State()[0].LastState, ShouldEqual, "idle")
+
+			clock.Add(time.Minute)
+			So(m.Tick(t.Context(), clock.Now()), ShouldBeNil)
+			So(runs, ShouldEqual, 4)
+		})
+
+		Convey("Test enable", func() {
+			So(m.Enable("f1"), ShouldBeNil)
+			st := m.State()[0]
+			So(st.LastState, ShouldEqual, "idle")
+			So(st.Failures, ShouldEqual, 0)
+
+			clock.Add(time.Minute)
+			_ = m.Tick(t.Context(), clock.Now())
+			So(runs, ShouldEqual, 3)
+			So(errors.Is(m.Enable("f2"), ErrNotFound), ShouldBeTrue)
+		})
+	})
+}
+
+func TestDisableAfterFailures_ManualOnly(t *testing.T) {
+	Convey("Test auto-disable doesn't affect manual-only jobs", t, func() {
+		m := NewManager()
+		var fail atomic.Bool
+		fn := func(context.Context) error {
+			if fail.Load() {
+				return errors.New("failed")
+			}
+			return nil
+		}
+		m.AddFunc("f1", "", fn, DisableAfterFailures(1))
+		m.AddFunc("f2", "disabled", fn, DisableAfterFailures(1))
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+
+		for _, name := range []string{"f1", "f2"} {
+			fail.Store(true)
+			So(m.ManualRun(t.Context(), name), ShouldNotBeNil)
+			fail.Store(false)
+			So(m.ManualRun(t.Context(), name), ShouldBeNil)
+		}
+
+		for _, st := range m.State() {
+			So(st.LastState, ShouldEqual, "idle")
+			So(st.NextRun.IsZero(), ShouldBeTrue)
+		}
+		So(m.cron.Entries(), ShouldBeEmpty)
+	})
+}
+
+func TestManager_EnableSchedule(t *testing.T) {
+	Convey("Test enable job with schedule", t, func() {
+		m := NewManager()
+		m.AddFunc("f1", "disabled", newCronFunc("f1"))
+		m.AddFunc("f2", "", newCronFunc("f2"))
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+
+		st := m.State()
+		So(st[0].LastState, ShouldEqual, "disabled")
+		So(st[1].LastState, ShouldEqual, "unscheduled")
+
+		var buf strings.Builder
+		m.TextSchedulePlain(&buf)
+		So(buf.String(), ShouldContainSubstring, "disabled  never  -         disabled\n")
+		So(buf.String(), ShouldContainSubstring, "never  -         unscheduled\n")
+
+		So(errors.Is(m.Reschedule("f1", "disabled"), ErrInvalidSchedule), ShouldBeTrue)
+		So(errors.Is(m.Reschedule("f1", "* * *"), ErrInvalidSchedule), ShouldBeTrue)
+		So(m.Reschedule("f3", "* * * * *"), ShouldEqual, ErrNotFound)
+
+		So(m.Reschedule("f1", "0 3 * * *"), ShouldBeNil)
+		So(m.Reschedule("f2", "@hourly"), ShouldBeNil)
+		st = m.State()
+		So(st[0].LastState, ShouldEqual, "idle")
+		So(st[0].Schedule, ShouldEqual, "0 3 * * *")
+		So(st[0].NextRun.IsZero(), ShouldBeFalse)
+		So(st[1].LastState, ShouldEqual, "idle")
+		So(st[1].NextRun.IsZero(), ShouldBeFalse)
+
+		// replace schedule
+		So(m.Reschedule("f2", "0 4 * * *"), ShouldBeNil)
+		So(m.State()[1].NextRun.Hour(), ShouldEqual, 4)
+		So(m.cron.Entries(), ShouldHaveLength, 2)
+	})
+}
+
+func TestManager_Disable(t *testing.T) {
+	Convey("Test disable and enable job", t, func() {
+		m := NewManager()
+		m.AddFunc("f1", "* * * * *", newCronFunc("f1"), DisableAfterFailures(3))
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+
+		So(m.Disable("f2"), ShouldEqual, ErrNotFound)
+		So(m.Disable("f1"), ShouldBeNil)
+		st := m.State()[0]
+		So(st.LastState, ShouldEqual, "disabled")
+		So(st.SkipKind, ShouldEqual, SkipDisabled)
+		So(st.NextRun.IsZero(), ShouldBeTrue)
+
+		// successful manual run doesn't resume disabled job
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		So(m.cron.Entries(), ShouldBeEmpty)
+		st = m.State()[0]
+		So(st.LastState, ShouldEqual, "disabled")
+		So(st.SkipReason, ShouldEqual, "disabled manually")
+		So(st.Disabled, ShouldBeTrue)
+		So(m.Summary().Disabled, ShouldEqual, 1)
+
+		So(m.Enable("f1"), ShouldBeNil)
+		st = m.State()[0]
+		So(st.LastState, ShouldEqual, "idle")
+		So(st.Disabled, ShouldBeFalse)
+		So(st.NextRun.IsZero(), ShouldBeFalse)
+	})
+
+	Convey("Test disable before run", t, func() {
+		m := NewManager()
+		m.AddFunc("f1", "* * * * *", newCronFunc("f1"))
+		So(m.Disable("f1"), ShouldBeNil)
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+
+		So(m.cron.Entries(), ShouldBeEmpty)
+		So(m.State()[0].Disabled, ShouldBeTrue)
+
+		So(m.Enable("f1"), ShouldBeNil)
+		So(m.cron.Entries(), ShouldHaveLength, 1)
+	})
+}
@@ -0,0 +1,100 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// breaker is a circuit breaker state of one job.
+type breaker struct {
+	failures  []time.Time
+	openUntil time.Time // zero if closed, in the past if half-open
+	probing   bool
+}
+
+// WithCircuitBreaker skips job runs for cooldown after threshold failures within window (consecutive failures if
+// window is 0). After cooldown one probe run is allowed: success closes the breaker, failure opens it again.
+// Skips are reported with reason "breaker open until 14:32" and counted in app_cron_breaker_skipped_total metric
+// (with app label if WithMetrics is added before).
+// Breaker state is shown in State.Breaker and UI until the breaker is closed.
+func WithCircuitBreaker(threshold int, window, cooldown time.Duration) MiddlewareFunc {
+	breakers := map[string]*breaker{}
+	mu := sync.Mutex{}
+
+	return func(next Func) Func {
+		return func(ctx context.Context) error {
+			name, clock := NameFromContext(ctx), clockFromContext(ctx)
+			app, _ := metricsAppFromContext(ctx)
+
+			mu.Lock()
+			b, ok := breakers[name]
+			if !ok {
+				b = &breaker{}
+				breakers[name] = b
+			}
+
+			switch {
+			case clock.Now().Before(b.openUntil):
+				mu.Unlock()
+				metricBreakerSkipped().WithLabelValues(app, name).Inc()
+				return newSkipError(SkipGuard, "breaker open until %s", b.openUntil.Format("15:04"))
+			case b.probing:
+				mu.Unlock()
+				metricBreakerSkipped().WithLabelValues(app, name).Inc()
+				return newSkipError(SkipGuard, "breaker half-open, probe run in progress")
+			case !b.openUntil.IsZero():
+				b.probing = true
+				setBreakerState(ctx, "half-open, probe run")
+			}
+			mu.Unlock()
+
+			err := next(ctx)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			now, probe := clock.Now(), b.probing
+			b.probing = false
+			switch {
+			case errors.Is(err, ErrSkipped):
+			case err == nil && probe:
+				b.openUntil, b.failures = time.Time{}, nil
+				setBreakerState(ctx, "")
+			case err == nil && window == 0:
+				b.failures = nil
+			case err != nil && probe:
+				b.openUntil = now.Add(cooldown)
+				setBreakerState(ctx, "open until "+b.openUntil.Format("15:04"))
+			case err != nil:
+				b.failures = append(b.failures, now)
+				for window > 0 && len(b.failures) > 0 && now.Sub(b.failures[0]) >= window {
+					b.failures = b.failures[1:]
+				}
+
+				if len(b.failures) >= threshold {
+					b.openUntil, b.failures = now.Add(cooldown), nil
+					setBreakerState(ctx, "open until "+b.openUntil.Format("15:04"))
+				}
+			}
+
+			return err
+		}
+	}
+}
+
+// setBreakerState sets circuit breaker state of the job, empty state means closed.
+func setBreakerState(ctx context.Context, state string) {
+	if fn, ok := ctx.Value(breakerKey).(func(string)); ok {
+		fn(state)
+	}
+}
+
+// setBreaker sets job circuit breaker state.
+func (cm *Manager) setBreaker(idx int, state string) {
+	cm.muState.Lock()
+	defer cm.muState.Unlock()
+
+	cm.jobs[idx].breaker = state
+}
@@ -0,0 +1,106 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithCircuitBreaker(t *testing.T) {
+	Convey("Test circuit breaker", t, func() {
+		clock := &testClock{now: time.Date(2025, 1, 1, 14, 0, 0, 0, time.UTC)}
+
+		var runs int
+		var fail = true
+		f := WithCircuitBreaker(2, time.Hour, 30*time.Minute)(func(context.Context) error {
+			runs++
+			if fail {
+				return errors.New("failed")
+			}
+			return nil
+		})
+		ctx := newClockContext(NewNameContext(t.Context(), "f1"), clock)
+		ctx = context.WithValue(ctx, metricsAppCtx, "test-breaker")
+
+		So(f(ctx), ShouldNotBeNil)
+		clock.Add(time.Minute)
+		So(f(ctx), ShouldNotBeNil)
+		So(runs, ShouldEqual, 2)
+
+		// open
+		clock.Add(time.Minute)
+		err := f(ctx)
+		So(errors.Is(err, ErrSkipped), ShouldBeTrue)
+		So(err.Error(), ShouldEqual, "skipped: breaker open until 14:31")
+		So(metricValue("app_cron_breaker_skipped_total", map[string]string{"app": "test-breaker", "cron": "f1"}), ShouldBeGreaterThan, 0)
+		So(runs, ShouldEqual, 2)
+
+		// other jobs are not affected
+		So(f(newClockContext(NewNameContext(t.Context(), "f2"), clock)), ShouldNotBeNil)
+		So(runs, ShouldEqual, 3)
+
+		Convey("Test failed probe opens breaker", func() {
+			clock.Add(30 * time.Minute)
+			So(errors.Is(f(ctx), ErrSkipped), ShouldBeFalse)
+			So(runs, ShouldEqual, 4)
+			So(errors.Is(f(ctx), ErrSkipped), ShouldBeTrue)
+		})
+
+		Convey("Test successful probe closes breaker", func() {
+			fail = false
+			clock.Add(30 * time.Minute)
+			So(f(ctx), ShouldBeNil)
+			So(f(ctx), ShouldBeNil)
+			So(runs, ShouldEqual, 5)
+		})
+	})
+
+	Convey("Test consecutive failures", t, func() {
+		clock := &testClock{now: time.Date(2025, 1, 1, 14, 0, 0, 0, time.UTC)}
+		results := []error{errors.New("failed"), nil, errors.New("failed"), errors.New("failed")}
+		f := WithCircuitBreaker(2, 0, time.Minute)(func(context.Context) error {
+			err := results[0]
+			results = results[1:]
+			return err
+		})
+		ctx := newClockContext(NewNameContext(t.Context(), "f1"), clock)
+
+		So(f(ctx), ShouldNotBeNil)
+		So(f(ctx), ShouldBeNil)
+		So(f(ctx), ShouldNotBeNil)
+		So(f(ctx), ShouldNotBeNil)
+		So(errors.Is(f(ctx), ErrSkipped), ShouldBeTrue)
+	})
+}
+
+func TestManager_BreakerState(t *testing.T) {
+	Convey("Test circuit breaker state in State", t, func() {
+		clock := &testClock{now: time.Date(2025, 1, 1, 14, 0, 30, 0, time.UTC)}
+		m := NewManager(WithClock(clock), WithManualTicker())
+		m.Use(WithCircuitBreaker(1, 0, time.Hour))
+
+		var fail atomic.Bool
+		fail.Store(true)
+		m.AddFunc("f1", "* * * * *", func(context.Context) error {
+			if fail.Load() {
+				return errors.New("failed")
+			}
+			return nil
+		})
+		So(m.Run(t.Context()), ShouldBeNil)
+		So(m.State()[0].Breaker, ShouldBeEmpty)
+
+		clock.Add(time.Minute)
+		So(m.Tick(t.Context(), clock.Now()), ShouldNotBeNil)
+		So(m.State()[0].Breaker, ShouldEqual, "open until 15:01")
+
+		fail.Store(false)
+		clock.Add(time.Hour)
+		So(m.Tick(t.Context(), clock.Now()), ShouldBeNil)
+		So(m.State()[0].Breaker, ShouldBeEmpty)
+	})
+}
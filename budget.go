@@ -0,0 +1,87 @@
+package cron
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// budget accounts run durations in a sliding window.
+type budget struct {
+	mu     sync.Mutex
+	window time.Duration
+	limit  time.Duration
+	runs   []budgetRun // ordered by end time
+}
+
+type budgetRun struct {
+	end      time.Time
+	duration time.Duration
+}
+
+// used returns spent budget at now and time when spent budget drops below limit.
+func (b *budget) used(now time.Time) (time.Duration, time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// forget runs outside the window
+	i := 0
+	for i < len(b.runs) && now.Sub(b.runs[i].end) >= b.window {
+		i++
+	}
+	b.runs = b.runs[i:]
+
+	var used time.Duration
+	for _, r := range b.runs {
+		used += r.duration
+	}
+
+	left, resetsAt := used, now
+	for _, r := range b.runs {
+		if left < b.limit {
+			break
+		}
+		left -= r.duration
+		resetsAt = r.end.Add(b.window)
+	}
+
+	return used, resetsAt
+}
+
+func (b *budget) add(end time.Time, d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.runs = append(b.runs, budgetRun{end: end, duration: d})
+}
+
+// WithBudget limits total execution time of all jobs in the chain to budget per sliding window, e.g. 10 minutes per hour.
+// Runs are accounted after they finish, when budget is exhausted further runs are skipped with
+// "budget exhausted, resets at 15:04" reason. Use Unless(MaintenanceFromContext, WithBudget(...)) to exempt
+// maintenance jobs. If WithMetrics is added before, remaining budget is tracked in app_cron_budget_remaining_seconds metric.
+func WithBudget(window, limit time.Duration) MiddlewareFunc {
+	b := &budget{window: window, limit: limit}
+
+	return func(next Func) Func {
+		return func(ctx context.Context) error {
+			clock := clockFromContext(ctx)
+			app, withMetrics := metricsAppFromContext(ctx)
+
+			used, resetsAt := b.used(clock.Now())
+			if used >= limit {
+				return newSkipError(SkipLimit, "budget exhausted, resets at %s", resetsAt.Format("15:04"))
+			}
+
+			start := clock.Now()
+			err := next(ctx)
+			b.add(clock.Now(), clock.Since(start))
+
+			if withMetrics {
+				used, _ = b.used(clock.Now())
+				metricBudgetRemaining().WithLabelValues(app, NameFromContext(ctx)).Set(max(limit-used, 0).Seconds())
+			}
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithBudget(t *testing.T) {
+	Convey("Test execution budget shared between jobs", t, func() {
+		clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+		m := NewManager(WithClock(clock))
+		m.Use(WithMetrics("test-budget"), Unless(MaintenanceFromContext, WithBudget(time.Hour, 10*time.Minute)))
+
+		work := func(context.Context) error {
+			clock.Add(4 * time.Minute)
+			return nil
+		}
+		m.AddFunc("f1", "", work)
+		m.AddFunc("f2", "", work)
+		m.AddMaintenanceFunc("m1", "", work)
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		So(m.ManualRun(t.Context(), "f2"), ShouldBeNil)
+		So(metricValue("app_cron_budget_remaining_seconds", map[string]string{"app": "test-budget", "cron": "f2"}), ShouldEqual, 120)
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+
+		So(errors.Is(m.ManualRun(t.Context(), "f2"), ErrSkipped), ShouldBeTrue)
+		So(m.State()[1].SkipReason, ShouldEqual, "budget exhausted, resets at 13:04")
+		So(m.ManualRun(t.Context(), "m1"), ShouldBeNil)
+
+		clock.Add(time.Hour)
+		So(m.ManualRun(t.Context(), "f2"), ShouldBeNil)
+	})
+}
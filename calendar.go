@@ -0,0 +1,54 @@
+package cron
+
+import (
+	"context"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Calendar decides whether a day is a working day. Implement it with your own holiday tables.
+type Calendar interface {
+	IsWorkingDay(t time.Time) bool
+}
+
+// WeekdayCalendar treats Monday-Friday as working days.
+type WeekdayCalendar struct{}
+
+// IsWorkingDay implements Calendar.
+func (WeekdayCalendar) IsWorkingDay(t time.Time) bool {
+	wd := t.Weekday()
+	return wd != time.Saturday && wd != time.Sunday
+}
+
+// SkipNonWorkingDays skips job runs on non-working days of cal.
+func SkipNonWorkingDays(cal Calendar) JobOption {
+	return func(j *job) {
+		j.calendar = cal
+		j.middleware = append(j.middleware, func(next Func) Func {
+			return func(ctx context.Context) error {
+				if !cal.IsWorkingDay(clockFromContext(ctx).Now()) {
+					return newSkipError(SkipWindow, "holiday")
+				}
+
+				return next(ctx)
+			}
+		})
+	}
+}
+
+// nextWorkingRun returns first activation of s starting from t that falls on a working day.
+// It returns zero time if nothing was found within a year.
+func nextWorkingRun(cal Calendar, s cron.Schedule, t time.Time) time.Time {
+	for range 366 {
+		if t.IsZero() || cal.IsWorkingDay(t) {
+			return t
+		}
+
+		// jump to the first activation of the next day
+		y, m, d := t.Date()
+		t = s.Next(time.Date(y, m, d+1, 0, 0, 0, 0, t.Location()).Add(-time.Nanosecond))
+	}
+
+	return time.Time{}
+}
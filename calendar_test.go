@@ -0,0 +1,23 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNextWorkingRun(t *testing.T) {
+	Convey("Test next working run", t, func() {
+		s, err := cron.ParseStandard("*/10 * * * *")
+		So(err, ShouldBeNil)
+
+		cal := WeekdayCalendar{}
+		friday := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+		So(nextWorkingRun(cal, s, friday), ShouldEqual, friday)
+
+		saturday := time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC)
+		So(nextWorkingRun(cal, s, saturday), ShouldEqual, time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC))
+	})
+}
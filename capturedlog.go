@@ -0,0 +1,127 @@
+package cron
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+const (
+	capturedLogKey contextKey = "capturedLog"
+	logRingKey     contextKey = "logRing"
+)
+
+// maxLogLineLen is a max length of captured log line, longer lines are truncated.
+const maxLogLineLen = 4 << 10
+
+// logRing is an io.Writer which keeps last n lines.
+type logRing struct {
+	mu      sync.Mutex
+	lines   []string
+	size    int
+	next    int    // position of the next line when the ring is full
+	partial []byte // line without newline yet
+}
+
+func newLogRing(size int) *logRing {
+	return &logRing{size: max(size, 1)}
+}
+
+// Write splits p into lines and keeps last lines.
+func (r *logRing) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			r.partial = appendLimited(r.partial, p)
+			break
+		}
+
+		r.add(string(appendLimited(r.partial, p[:i])))
+		r.partial, p = r.partial[:0], p[i+1:]
+	}
+
+	return n, nil
+}
+
+// appendLimited appends p to b up to maxLogLineLen.
+func appendLimited(b, p []byte) []byte {
+	if rest := maxLogLineLen - len(b); len(p) > rest {
+		p = p[:max(rest, 0)]
+	}
+
+	return append(b, p...)
+}
+
+func (r *logRing) add(line string) {
+	if len(r.lines) < r.size {
+		r.lines = append(r.lines, line)
+		return
+	}
+
+	r.lines[r.next] = line
+	r.next = (r.next + 1) % r.size
+}
+
+// Lines returns captured lines, oldest first.
+func (r *logRing) Lines() []string {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ll := make([]string, 0, len(r.lines))
+	ll = append(ll, r.lines[r.next:]...)
+	ll = append(ll, r.lines[:r.next]...)
+	if len(r.partial) > 0 {
+		ll = append(ll, string(r.partial))
+	}
+
+	return ll
+}
+
+// WithCapturedLog keeps last n log lines of each job written to CapturedLogFromContext writer.
+// Lines are shown in State.Log and on the job details page, each line is limited to 4KB.
+func WithCapturedLog(n int) MiddlewareFunc {
+	return func(next Func) Func {
+		return func(ctx context.Context) error {
+			var w io.Writer
+			if fn, ok := ctx.Value(logRingKey).(func(int) *logRing); ok {
+				w = fn(n)
+			} else {
+				w = newLogRing(n)
+			}
+
+			return next(context.WithValue(ctx, capturedLogKey, w))
+		}
+	}
+}
+
+// CapturedLogFromContext returns writer for job log lines captured by WithCapturedLog or io.Discard.
+// Use it with slog: slog.New(slog.NewTextHandler(cron.CapturedLogFromContext(ctx), nil)).
+func CapturedLogFromContext(ctx context.Context) io.Writer {
+	if w, ok := ctx.Value(capturedLogKey).(io.Writer); ok {
+		return w
+	}
+
+	return io.Discard
+}
+
+// logRing returns captured log of the job with n lines.
+func (cm *Manager) logRing(idx, n int) *logRing {
+	cm.muState.Lock()
+	defer cm.muState.Unlock()
+
+	j := cm.jobs[idx]
+	if j.log == nil || j.log.size != max(n, 1) {
+		j.log = newLogRing(n)
+	}
+
+	return j.log
+}
@@ -0,0 +1,53 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithCapturedLog(t *testing.T) {
+	Convey("Test last log lines of a job", t, func() {
+		m := NewManager()
+		m.Use(WithCapturedLog(3))
+		m.AddFunc("f1", "", func(ctx context.Context) error {
+			w := CapturedLogFromContext(ctx)
+			for i := range 4 {
+				fmt.Fprintf(w, "line %d\n", i)
+			}
+			slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{
+				ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+					if a.Key == slog.TimeKey {
+						return slog.Attr{}
+					}
+					return a
+				},
+			})).Info("done", "rows", 10)
+			fmt.Fprint(w, strings.Repeat("x", 5000))
+			return nil
+		})
+		m.AddFunc("f2", "", newCronFunc("f2"))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		st := m.State()
+		So(st[0].Log, ShouldHaveLength, 4)
+		So(st[0].Log[:3], ShouldResemble, []string{"line 2", "line 3", "level=INFO msg=done rows=10"})
+		So(st[0].Log[3], ShouldHaveLength, maxLogLineLen)
+		So(st[1].Log, ShouldBeEmpty)
+
+		r := httptest.NewRequest(http.MethodGet, "/?job=f1", nil)
+		r.Header.Set("Accept", "text/html")
+		w := httptest.NewRecorder()
+		m.Handler(w, r)
+		So(w.Body.String(), ShouldContainSubstring, "line 3\nlevel=INFO msg=done rows=10\n")
+
+		So(CapturedLogFromContext(t.Context()), ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,62 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// ErrChaos wraps failures and panics injected by WithChaos.
+var ErrChaos = errors.New("chaos")
+
+// ChaosOptions are options for WithChaosOptions.
+type ChaosOptions struct {
+	FailureRate  float64       // probability of injected error, [0, 1]
+	PanicRate    float64       // probability of injected panic, [0, 1]
+	ExtraLatency time.Duration // max random delay before run
+
+	// Rand returns pseudo-random number in [0, 1), default is math/rand/v2 Float64.
+	Rand func() float64
+}
+
+// WithChaos randomly injects errors, panics and latency into runs in devel environment (see WithDevel),
+// e.g. to exercise alerting and retries in staging. It is a no-op in production. Injected errors and panics
+// wrap ErrChaos. Rates out of [0, 1] are clamped to it.
+func WithChaos(failureRate, panicRate float64, extraLatency time.Duration) MiddlewareFunc {
+	return WithChaosOptions(ChaosOptions{FailureRate: failureRate, PanicRate: panicRate, ExtraLatency: extraLatency})
+}
+
+// WithChaosOptions is WithChaos with options, e.g. with deterministic random source for tests.
+func WithChaosOptions(opts ChaosOptions) MiddlewareFunc {
+	opts.FailureRate, opts.PanicRate = min(max(opts.FailureRate, 0), 1), min(max(opts.PanicRate, 0), 1)
+	if opts.Rand == nil {
+		opts.Rand = rand.Float64
+	}
+
+	return func(next Func) Func {
+		return func(ctx context.Context) error {
+			if !IsDevelFromContext(ctx) {
+				return next(ctx)
+			}
+
+			if opts.ExtraLatency > 0 {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-clockAfter(clockFromContext(ctx), time.Duration(opts.Rand()*float64(opts.ExtraLatency))):
+				}
+			}
+
+			switch {
+			case opts.Rand() < opts.PanicRate:
+				panic(fmt.Errorf("%w: injected panic", ErrChaos))
+			case opts.Rand() < opts.FailureRate:
+				return fmt.Errorf("%w: injected failure", ErrChaos)
+			}
+
+			return next(ctx)
+		}
+	}
+}
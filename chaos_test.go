@@ -0,0 +1,57 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithChaos(t *testing.T) {
+	Convey("Test chaos middleware", t, func() {
+		var rr []float64
+		next := func() float64 {
+			r := rr[0]
+			rr = rr[1:]
+			return r
+		}
+		m := NewManager()
+		m.Use(WithDevel(true), WithRecover(), WithChaosOptions(ChaosOptions{FailureRate: 0.5, PanicRate: 0.1, Rand: next}))
+		m.AddFunc("f1", "", newCronFunc("f1"))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		rr = []float64{0.05}
+		err := m.ManualRun(t.Context(), "f1")
+		var pe *PanicError
+		So(errors.As(err, &pe), ShouldBeTrue)
+		So(errors.Is(err, ErrChaos), ShouldBeTrue)
+
+		rr = []float64{0.5, 0.3}
+		So(errors.Is(m.ManualRun(t.Context(), "f1"), ErrChaos), ShouldBeTrue)
+
+		rr = []float64{0.5, 0.7}
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+
+		Convey("Test production and invalid rates", func() {
+			f := WithChaos(1, 0, 0)(func(context.Context) error { return nil })
+			So(f(NewIsDevelContext(t.Context(), false)), ShouldBeNil)
+			So(errors.Is(f(NewIsDevelContext(t.Context(), true)), ErrChaos), ShouldBeTrue)
+
+			f = WithChaos(1.5, -1, 0)(func(context.Context) error { return nil })
+			So(errors.Is(f(NewIsDevelContext(t.Context(), true)), ErrChaos), ShouldBeTrue)
+		})
+
+		Convey("Test extra latency uses manager clock", func() {
+			clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC), after: make(chan time.Duration)}
+			f := WithChaosOptions(ChaosOptions{ExtraLatency: time.Minute, Rand: func() float64 { return 0.5 }})(func(context.Context) error { return nil })
+
+			done := make(chan error)
+			go func() { done <- f(newClockContext(NewIsDevelContext(t.Context(), true), clock)) }()
+			So(<-clock.after, ShouldEqual, 30*time.Second)
+			clock.Add(30 * time.Second)
+			So(<-done, ShouldBeNil)
+		})
+	})
+}
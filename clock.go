@@ -0,0 +1,66 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const clockKey contextKey = "clock"
+
+// Clock provides current time. Use WithClock to override it in tests. Clock can also implement
+// After(d time.Duration) <-chan time.Time for waits in middleware, e.g. WithDelay and WithRetry,
+// otherwise they wait on real time.
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+}
+
+// afterClock is a Clock with timer, see Clock.
+type afterClock interface {
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is a Clock based on time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// WithClock sets clock for state tracking, UI and time-based middleware, including run durations in WithLogger,
+// WithSLog, WithSentry and WithMetrics. Default is a real clock. Scheduler itself uses real time: use
+// WithManualTicker and Tick to trigger scheduled runs in tests.
+func WithClock(c Clock) Option {
+	return func(cm *Manager) {
+		if c == nil {
+			cm.err = errors.New("invalid nil clock")
+			return
+		}
+
+		cm.clock = c
+	}
+}
+
+// newClockContext creates new context with clock.
+func newClockContext(ctx context.Context, c Clock) context.Context {
+	return context.WithValue(ctx, clockKey, c)
+}
+
+// clockFromContext returns clock set by Manager or real clock.
+func clockFromContext(ctx context.Context) Clock {
+	if c, ok := ctx.Value(clockKey).(Clock); ok {
+		return c
+	}
+
+	return realClock{}
+}
+
+// clockAfter waits for d on clock c if it has a timer or on real time.
+func clockAfter(c Clock, d time.Duration) <-chan time.Time {
+	if ac, ok := c.(afterClock); ok {
+		return ac.After(d)
+	}
+
+	return time.After(d)
+}
@@ -0,0 +1,196 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// testClock is a manually advanced Clock. Waits registered by After are reported to optional after channel.
+type testClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	after   chan time.Duration
+	waiters []testWaiter
+}
+
+type testWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Since(t time.Time) time.Duration { return c.Now().Sub(t) }
+
+func (c *testClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+
+	c.waiters = slices.DeleteFunc(c.waiters, func(w testWaiter) bool {
+		if w.at.After(c.now) {
+			return false
+		}
+		w.ch <- c.now
+		return true
+	})
+}
+
+func (c *testClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, testWaiter{at: c.now.Add(d), ch: ch})
+	c.mu.Unlock()
+
+	if c.after != nil {
+		c.after <- d
+	}
+
+	return ch
+}
+
+func TestWithClock(t *testing.T) {
+	Convey("Test manager with custom clock", t, func() {
+		clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+		m := NewManager(WithClock(clock))
+		m.Use(WithMinInterval(10 * time.Minute))
+		m.AddFunc("f1", "", func(context.Context) error {
+			clock.Add(time.Minute)
+			return nil
+		})
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		st := m.State()[0]
+		So(st.LastDuration, ShouldEqual, time.Minute)
+		So(st.LastUpdatedAt, ShouldEqual, time.Date(2025, 1, 1, 12, 1, 0, 0, time.UTC))
+
+		clock.Add(5 * time.Minute)
+		So(errors.Is(m.ManualRun(t.Context(), "f1"), ErrSkipped), ShouldBeTrue)
+		So(m.State()[0].SkipReason, ShouldEqual, "succeeded 5m0s ago, eligible in 5m0s")
+
+		clock.Add(5 * time.Minute)
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+	})
+
+	Convey("Test nil clock", t, func() {
+		So(NewManager(WithClock(nil)).Run(t.Context()), ShouldNotBeNil)
+	})
+
+	Convey("Test middleware durations with custom clock", t, func() {
+		clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+		lg := &testLogger{}
+		var line string
+		m := NewManager(WithClock(clock))
+		m.Use(WithSLog(lg), WithLogger(func(format string, v ...any) { line = fmt.Sprintf(format, v...) }, "test"))
+		m.AddFunc("f1", "", func(context.Context) error {
+			clock.Add(90 * time.Second)
+			return nil
+		})
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		_, args := lg.last()
+		So(args[:4], ShouldResemble, []any{"job", "f1", "duration", 90 * time.Second})
+		So(line, ShouldStartWith, "cron job finished job=f1 duration=1m30s ")
+	})
+}
+
+func TestStartTimeFromContext(t *testing.T) {
+	Convey("Test run start time in context", t, func() {
+		clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+		m := NewManager(WithClock(clock))
+
+		var startedAt time.Time
+		m.AddFunc("f1", "", func(ctx context.Context) error {
+			startedAt = StartTimeFromContext(ctx)
+			return nil
+		})
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		So(StartTimeFromContext(t.Context()).IsZero(), ShouldBeTrue)
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		So(startedAt, ShouldEqual, clock.Now())
+	})
+}
+
+func TestManager_Tick(t *testing.T) {
+	Convey("Test manual ticker", t, func() {
+		clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 30, 0, time.UTC)}
+		m := NewManager(WithClock(clock), WithManualTicker())
+
+		runs := map[string]int{}
+		counter := func(name string) Func {
+			return func(context.Context) error {
+				runs[name]++
+				return nil
+			}
+		}
+		m.AddFunc("minutely", "* * * * *", counter("minutely"))
+		m.AddFunc("hourly", "0 * * * *", counter("hourly"))
+		m.AddFunc("failed", "*/5 * * * *", func(context.Context) error { return errors.New("failed") })
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		clock.Add(time.Minute)
+		So(m.Tick(t.Context(), clock.Now()), ShouldBeNil)
+		So(runs, ShouldResemble, map[string]int{"minutely": 1})
+
+		clock.Add(time.Hour)
+		err := m.Tick(t.Context(), clock.Now())
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldEqual, "failed: failed")
+		So(runs, ShouldResemble, map[string]int{"minutely": 2, "hourly": 1})
+		So(m.State()[2].Failures, ShouldEqual, 1)
+	})
+}
+
+func TestManager_DryRun(t *testing.T) {
+	Convey("Test dry-run mode", t, func() {
+		clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 30, 0, time.UTC)}
+		lg := &testLogger{}
+
+		var runs int
+		fn := func(context.Context) error {
+			runs++
+			return nil
+		}
+
+		Convey("Test scheduled runs are only logged", func() {
+			m := NewManager(WithClock(clock), WithManualTicker(), WithManagerLogger(lg), WithDryRun(false))
+			m.AddFunc("f1", "* * * * *", fn)
+			So(m.Run(t.Context()), ShouldBeNil)
+
+			clock.Add(time.Minute)
+			So(m.Tick(t.Context(), clock.Now()), ShouldBeNil)
+			So(runs, ShouldEqual, 0)
+			So(m.State()[0].LastState, ShouldEqual, "dry-run")
+			msg, args := lg.last()
+			So(msg, ShouldEqual, "cron job would run")
+			So(args, ShouldResemble, []any{"job", "f1"})
+
+			So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+			So(runs, ShouldEqual, 1)
+		})
+
+		Convey("Test manual runs in dry-run", func() {
+			m := NewManager(WithDryRun(true))
+			m.AddFunc("f1", "* * * * *", fn)
+			So(m.Run(t.Context()), ShouldBeNil)
+			defer m.Stop()
+
+			So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+			So(runs, ShouldEqual, 0)
+		})
+	})
+}
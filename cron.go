@@ -4,8 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"html/template"
+	"net/http"
+	"reflect"
+	"runtime"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -14,17 +20,38 @@ import (
 const (
 	maintenanceKey contextKey = "maintenance"
 	nameKey        contextKey = "name"
+	scheduleKey    contextKey = "schedule"
+	managerKey     contextKey = "manager"
+	lastSuccessKey contextKey = "lastSuccess"
+	startTimeKey   contextKey = "startTime"
+	stateFuncKey   contextKey = "stateFunc"
+	runMessageKey  contextKey = "runMessage"
+	breakerKey     contextKey = "breaker"
+	metricsAppKey  contextKey = "metricsAppFunc"
+	loggerKey      contextKey = "logger"
 
-	stateIdle     cronState = "idle"
-	stateDisabled cronState = "disabled"
-	stateRunning  cronState = "running"
-	stateSkipped  cronState = "skipped"
+	stateIdle        cronState = "idle"
+	stateDisabled    cronState = "disabled"    // schedule is "disabled": job is intentionally off
+	stateUnscheduled cronState = "unscheduled" // schedule is not set yet, job runs only manually
+	stateRunning     cronState = "running"
+	stateSkipped     cronState = "skipped"
+	stateOverrun     cronState = "overrun"   // still running after max duration
+	stateWaiting     cronState = "waiting"   // blocked by middleware, e.g. waiting for maintenance lock
+	stateQueued      cronState = "queued"    // waiting for its turn in WithSerial queue or worker pool
+	stateStuck       cronState = "stuck"     // running longer than expected runtime, see ExpectRuntime
+	stateDryRun      cronState = "dry-run"   // job was triggered in dry-run mode, see WithDryRun
+	stateCompleted   cronState = "completed" // one-shot job has run, see AddAt
+	stateFailed      cronState = "failed"    // last run failed, see WithFailedState
 )
 
 var (
 	ErrSkipped   = errors.New("skipped")
 	ErrNotFound  = errors.New("job not found")
 	ErrDuplicate = errors.New("duplicate cron name")
+
+	ErrInvalidSchedule = errors.New("invalid schedule")
+	ErrNotRunning      = errors.New("job is not running")
+	ErrCancelled       = errors.New("cancelled by operator") // run is cancelled by Manager.Cancel
 )
 
 type (
@@ -35,31 +62,148 @@ type (
 	MiddlewareFunc func(Func) Func
 	LogPrintf      func(format string, v ...interface{})
 
+	// Option configures Manager.
+	Option func(*Manager)
+
+	// JobOption configures a single job on registration.
+	JobOption func(*job)
+
 	Runner interface {
 		Run(context.Context) error
 	}
 )
 
+// isActive checks if job is currently running.
+func (s cronState) isActive() bool { return s == stateRunning || s == stateOverrun || s == stateStuck }
+
 type Schedule string
 
 func (ss Schedule) String() string { return string(ss) }
-func (ss Schedule) IsActive() bool { return ss != Schedule(stateDisabled) && ss != "" }
+func (ss Schedule) IsActive() bool {
+	spec := ss.withoutComment()
+	return spec != string(stateDisabled) && spec != ""
+}
+
+// inactiveState returns state for inactive schedule: disabled for "disabled" keyword, unscheduled for empty schedule.
+func (ss Schedule) inactiveState() cronState {
+	if ss.withoutComment() == string(stateDisabled) {
+		return stateDisabled
+	}
+	return stateUnscheduled
+}
+
+// withoutComment returns schedule without trailing "# comment". Comment must be separated by whitespace,
+// so "#" inside expression (e.g. "5#3") is kept.
+func (ss Schedule) withoutComment() string {
+	s := string(ss)
+	for i := range len(s) {
+		if s[i] == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t') {
+			s = s[:i]
+			break
+		}
+	}
+
+	return strings.TrimSpace(s)
+}
 
 // Manager is a Cron manager with context and middleware support.
 type Manager struct {
 	cron       *cron.Cron
 	middleware []MiddlewareFunc
-	jobs       []job
-	muState    sync.Mutex
+	jobs       []*job
+	muState    sync.RWMutex
+
+	name           string
+	clock          Clock
+	healthFailures int // consecutive failures for unhealthy job
+	store          Store
+	logger         Logger
+	schedulerLog   bool // see WithSchedulerLog
+	recoverPanics  bool // see WithRecoverPanics
+	failedState    bool // see WithFailedState
+	pusher         *metricsPusher
+
+	watchdogInterval time.Duration
+
+	maintenanceWindow *window
+	maintenanceDefer  bool
+	err               error // options error, returned on Run
+	draining          atomic.Bool
+	paused            bool // see PauseAll
+	sharding          *sharding
+
+	history     *MemoryHistory
+	historySink HistorySink
+
+	locker    Locker // manual runs locker, see WithManualRunLocker
+	lockOwner string
+	lockTTL   time.Duration
+
+	leader              Leader
+	leaderCheckInterval time.Duration // see CancelOnLoss
+
+	extendedSyntax bool
+	parser         cron.ScheduleParser
+
+	manualTicker bool
+	dryRun       bool
+	dryRunManual bool
+	lastTick     time.Time
+	startedAt    time.Time       // Run time
+	runCtx       context.Context // Run context for scheduled jobs
+
+	stateHandlers []func(State) // see OnStateChange
+	events        eventBus      // see EventsHandler
+	htmlTemplate  *template.Template
+	gzip          bool                                 // see WithGzip
+	manualRunGET  bool                                 // see WithManualRunGET
+	handlerAuth   func(r *http.Request) (string, bool) // see HandlerAuth
+	authChallenge string                               // WWW-Authenticate header on auth failure, see HandlerBasicAuth
+	pool          *workerPool                          // see WithWorkerPool
+
+	muRuns sync.Mutex
+	runs   map[*activeRun]struct{} // running invocations, see Shutdown
+
+	muResources sync.Mutex
+	resources   map[string]*resource // see WithResource
+
+	background     context.Context    // Run context of watchdog, pool workers and deferred runs
+	stopBackground context.CancelFunc // stops watchdog, pool workers and deferred runs
+	wgBackground   sync.WaitGroup     // watchdog, pool workers and runs started outside robfig/cron, see Reset
 }
 
 type job struct {
 	id            cron.EntryID // cron id after AddFunc in robfig/cron
 	name          string
+	key           string   // stable job key, see Key
+	description   string   // see Description
+	tags          []string // see Tags
+	disableAfter  int      // see DisableAfterFailures
+	autoDisabled  bool     // scheduling is stopped by DisableAfterFailures or Disable
+	userDisabled  bool     // see Manager.Disable, successful run doesn't resume it
 	schedule      Schedule
+	spec          string        // resolved schedule, see resolveHash
+	sched         cron.Schedule // parsed schedule
 	isMaintenance bool
 	fn            Func
-	cronFn        Func
+	cronFn        Func             // main job function with middleware and state tracking
+	schedFn       Func             // function for scheduled runs: cronFn or dry-run
+	middleware    []MiddlewareFunc // per-job middleware, applied after Manager's middleware
+	window        *window
+	calendar      Calendar
+	maxDuration   time.Duration
+	shutdownGrace time.Duration // see ShutdownGrace
+	expRuntime    time.Duration // expected max runtime for watchdog
+	env           string        // environment gate, see OnlyInDevel
+	catchUp       CatchUpPolicy
+	runOnStart    bool                                    // see RunOnStart
+	deferred      bool                                    // deferred run is planned, see WithMaintenanceDefer
+	owner         string                                  // last computed replica, see WithSharding
+	breaker       string                                  // circuit breaker state, see WithCircuitBreaker
+	metricsApp    string                                  // app label of WithMetrics, known after the first run
+	log           *logRing                                // see WithCapturedLog
+	contexts      []func(context.Context) context.Context // per-job context values
+	err           error                                   // job options error, returned on validation
 
 	// last states
 	last jobState
@@ -69,34 +213,207 @@ type jobState struct {
 	state     cronState
 	err       error
 	updatedAt time.Time
+	startedAt time.Time
+	successAt time.Time // last successful run finish
 	duration  time.Duration
+	failures  int           // consecutive failures
+	failErr   error         // last failure error, see WithFailedState
+	lastRun   time.Time     // last scheduled run restored from Store
+	missed    int           // missed runs since lastRun
+	reason    string        // skip reason
+	kind      SkipKind      // skip kind
+	message   string        // run message, see SetRunMessage
+	stack     []byte        // panic stack
+	resume    cronState     // active state to restore after waiting in middleware
+	waitedAt  time.Time     // start of waiting in middleware
+	waited    time.Duration // time spent waiting in middleware during the run
+}
+
+// SkipKind is a category of skipped run, see SkipError.
+type SkipKind string
+
+const (
+	SkipActive      SkipKind = "active"      // previous run is still in progress, see WithSkipActive
+	SkipMaintenance SkipKind = "maintenance" // blocked by maintenance lock or outside maintenance window
+	SkipWindow      SkipKind = "window"      // outside allowed window or on non-working day
+	SkipDisabled    SkipKind = "disabled"    // disabled by environment, flag, WithOnce or after failures
+	SkipGuard       SkipKind = "guard"       // health check, dependency, circuit breaker or backoff
+	SkipLimit       SkipKind = "limit"       // run limits: interval, rate, quota, budget, resource or cache
+	SkipReplica     SkipKind = "replica"     // job runs on another replica
+	SkipDraining    SkipKind = "draining"    // manager is drained, see Manager.Drain
+	SkipIgnored     SkipKind = "ignored"     // job error is converted to skip, see WithSkipErrors
+)
+
+// SkipError is ErrSkipped with a kind, a reason and an optional cause. Use errors.As to get it from job error.
+type SkipError struct {
+	Kind   SkipKind
+	Reason string
+	Err    error // cause of the skip if any, e.g. guard check error
 }
 
-func NewManager() *Manager {
-	return &Manager{
-		cron: cron.New(),
+func (e SkipError) Error() string        { return "skipped: " + e.Reason }
+func (e SkipError) Is(target error) bool { return target == ErrSkipped }
+func (e SkipError) Unwrap() error        { return e.Err }
+
+// newSkipError returns ErrSkipped with kind and formatted reason.
+func newSkipError(kind SkipKind, format string, v ...any) error {
+	return SkipError{Kind: kind, Reason: fmt.Sprintf(format, v...)}
+}
+
+// wrapSkipError returns ErrSkipped with kind, formatted reason and err as its cause.
+func wrapSkipError(err error, kind SkipKind, format string, v ...any) error {
+	return SkipError{Kind: kind, Reason: fmt.Sprintf(format, v...), Err: err}
+}
+
+func NewManager(opts ...Option) *Manager {
+	cm := &Manager{
+		clock:          realClock{},
+		healthFailures: 1,
+
+		watchdogInterval: 10 * time.Second,
+		history:          NewMemoryHistory(defaultHistoryDepth),
+	}
+
+	for _, opt := range opts {
+		opt(cm)
+	}
+	cm.cron = cm.newCron()
+
+	return cm
+}
+
+// newCron returns new robfig/cron instance.
+func (cm *Manager) newCron() *cron.Cron {
+	if cm.schedulerLog && cm.logger != nil {
+		return cron.New(cron.WithLogger(schedulerLogger{lg: cm.logger}))
+	}
+
+	return cron.New()
+}
+
+// WithName sets manager name. It is available in job context (see ManagerNameFromContext) and used for H schedules hashing.
+func WithName(name string) Option {
+	return func(cm *Manager) {
+		cm.name = name
+	}
+}
+
+// WithManagerLogger sets logger for Manager events, e.g. stuck jobs. It doesn't log runs: use WithSLog for that.
+func WithManagerLogger(lg Logger) Option {
+	return func(cm *Manager) {
+		cm.logger = lg
+	}
+}
+
+// WithSchedulerLog routes internal robfig/cron scheduler events (start, wake, run, added, removed) to
+// WithManagerLogger logger, e.g. to debug missed runs. Jobs are referenced by entry id, see State.ID.
+func WithSchedulerLog() Option {
+	return func(cm *Manager) {
+		cm.schedulerLog = true
+	}
+}
+
+// schedulerLogger adapts Logger to robfig/cron logger.
+type schedulerLogger struct {
+	lg Logger
+}
+
+func (l schedulerLogger) Info(msg string, keysAndValues ...any) {
+	l.lg.Print(context.Background(), "cron scheduler "+msg, keysAndValues...)
+}
+
+func (l schedulerLogger) Error(err error, msg string, keysAndValues ...any) {
+	l.lg.Error(context.Background(), "cron scheduler "+msg, append(keysAndValues, "err", err)...)
+}
+
+// WithRecoverPanics returns panics escaped all middleware as PanicError instead of crashing the process.
+// Without it such panics are recorded in job state and raised again. Use WithRecover or WithSentry to handle
+// panics in middleware chain.
+func WithRecoverPanics() Option {
+	return func(cm *Manager) {
+		cm.recoverPanics = true
+	}
+}
+
+// WithDryRun enables dry-run mode: scheduled runs are only logged via WithManagerLogger and
+// set dry-run state instead of invoking job. Manual runs are executed unless includeManual is true.
+func WithDryRun(includeManual bool) Option {
+	return func(cm *Manager) {
+		cm.dryRun, cm.dryRunManual = true, includeManual
+	}
+}
+
+// WithManualTicker doesn't start scheduler on Run: jobs are executed by Tick calls. Use it in tests.
+func WithManualTicker() Option {
+	return func(cm *Manager) {
+		cm.manualTicker = true
+	}
+}
+
+// WithFailedState sets failed state instead of idle after failed run. Job stays failed until the next successful run,
+// skipped runs don't clear it. Without the option failed job is idle with LastErr.
+func WithFailedState() Option {
+	return func(cm *Manager) {
+		cm.failedState = true
+	}
+}
+
+// WithHealthThreshold sets number of consecutive failures after which job is unhealthy, see Healthy. Default is 1.
+func WithHealthThreshold(failures int) Option {
+	return func(cm *Manager) {
+		cm.healthFailures = max(failures, 1)
 	}
 }
 
 // AddFunc adds func to cron.
-func (cm *Manager) AddFunc(name string, schedule Schedule, fn Func) {
-	cm.jobs = append(cm.jobs, newJob(name, schedule, fn, false))
+func (cm *Manager) AddFunc(name string, schedule Schedule, fn Func, opts ...JobOption) {
+	cm.jobs = append(cm.jobs, newJob(name, schedule, fn, false, opts...))
+}
+
+// AddFuncErr adds func to cron like AddFunc, but checks job right away: it returns ErrDuplicate if job name
+// (case-insensitive) or key already exists, or an error for invalid schedule or job options. Job is not added on error.
+func (cm *Manager) AddFuncErr(name string, schedule Schedule, fn Func, opts ...JobOption) error {
+	j := newJob(name, schedule, fn, false, opts...)
+	for i := range cm.jobs {
+		switch {
+		case strings.EqualFold(cm.jobs[i].name, j.name):
+			return fmt.Errorf("%w: %s", ErrDuplicate, j.name)
+		case cm.jobs[i].key == j.key:
+			return fmt.Errorf("%w key=%s: %s", ErrDuplicate, j.key, j.name)
+		}
+	}
+
+	if j.err != nil {
+		return fmt.Errorf("%w: %s", j.err, j.name)
+	}
+
+	if j.schedule.IsActive() {
+		if _, _, err := cm.parse(j.name, j.schedule); err != nil {
+			return fmt.Errorf("%w: %s", err, j.name)
+		}
+	}
+
+	cm.jobs = append(cm.jobs, j)
+	return nil
 }
 
 // Add adds Runner to cron.
-func (cm *Manager) Add(name string, schedule Schedule, r Runner) {
-	cm.AddFunc(name, schedule, r.Run)
+func (cm *Manager) Add(name string, schedule Schedule, r Runner, opts ...JobOption) {
+	cm.AddFunc(name, schedule, r.Run, opts...)
 }
 
 // AddMaintenanceFunc adds func to cron.
-func (cm *Manager) AddMaintenanceFunc(name string, schedule Schedule, fn Func) {
-	cm.jobs = append(cm.jobs, newJob(name, schedule, fn, true))
+func (cm *Manager) AddMaintenanceFunc(name string, schedule Schedule, fn Func, opts ...JobOption) {
+	cm.jobs = append(cm.jobs, newJob(name, schedule, fn, true, opts...))
 }
 
-// validateJobs checks jobs for unique names.
+// validateJobs checks jobs for unique names and parses their schedules.
 func (cm *Manager) validateJobs() (string, error) {
 	names := make(map[string]struct{}, len(cm.jobs))
-	for _, job := range cm.jobs {
+	keys := make(map[string]struct{}, len(cm.jobs))
+	for i := range cm.jobs {
+		job := cm.jobs[i]
+
 		// check for duplicates
 		n := strings.ToLower(job.name)
 		if _, ok := names[n]; ok {
@@ -104,123 +421,520 @@ func (cm *Manager) validateJobs() (string, error) {
 		}
 		names[n] = struct{}{}
 
+		if _, ok := keys[job.key]; ok {
+			return job.name, fmt.Errorf("%w key=%s", ErrDuplicate, job.key)
+		}
+		keys[job.key] = struct{}{}
+
+		// check job options
+		if job.err != nil {
+			return job.name, job.err
+		}
+
 		// parse schedule
 		if job.schedule.IsActive() {
-			_, err := cron.ParseStandard(job.schedule.String())
+			sch, spec, err := cm.parse(job.name, job.schedule)
 			if err != nil {
 				return job.name, err
 			}
+			job.sched, job.spec = sch, spec
 		}
 	}
 	return "", nil
 }
 
-// ManualRun runs a cron func with middlewares and context.
-func (cm *Manager) ManualRun(ctx context.Context, id string) error {
+// Schedule returns parsed job schedule or nil for disabled job.
+func (cm *Manager) Schedule(name string) (cron.Schedule, error) {
 	for i := range cm.jobs {
-		if strings.EqualFold(cm.jobs[i].name, id) {
-			// run found func
-			return cm.jobs[i].cronFn(ctx)
+		j := cm.jobs[i]
+		if !strings.EqualFold(j.name, name) {
+			continue
+		}
+
+		switch {
+		case !j.schedule.IsActive():
+			return nil, nil //nolint:nilnil // disabled job has no schedule
+		case j.sched != nil:
+			return j.sched, nil
+		}
+
+		// not parsed before Run
+		sch, _, err := cm.parse(j.name, j.schedule)
+		return sch, err
+	}
+
+	return nil, ErrNotFound
+}
+
+// ManualRun runs a cron func with middlewares and context. Job is found by key or name.
+func (cm *Manager) ManualRun(ctx context.Context, id string) error {
+	i := cm.jobIndex(id)
+	if i < 0 {
+		return ErrNotFound
+	}
+
+	// acquire shared lease
+	ctx = newManualContext(ctx)
+	if cm.locker != nil {
+		lctx, unlock, err := cm.lockManualRun(ctx, i)
+		if err != nil {
+			return err
 		}
+		defer unlock()
+		ctx = lctx
 	}
 
-	return ErrNotFound
+	// run found func, Run may set it concurrently
+	cm.muState.Lock()
+	fn := cm.jobs[i].cronFn
+	if cm.dryRun && cm.dryRunManual {
+		fn = cm.jobs[i].schedFn
+	}
+	cm.muState.Unlock()
+
+	return fn(ctx)
 }
 
 // Run is a main function that registers all jobs and starts robfig/cron in separate goroutine.
+// Scheduler is stopped when ctx is done.
 func (cm *Manager) Run(ctx context.Context) error {
+	if cm.err != nil {
+		return fmt.Errorf("invalid option: %w", cm.err)
+	}
+
 	// check for duplicate names and schedule error.
 	if name, err := cm.validateJobs(); name != "" {
 		return fmt.Errorf("%w: %s", err, name)
 	}
 
+	cm.checkMiddleware(ctx)
+	cm.logLint(ctx)
+
+	// restore states and calculate missed runs
+	if cm.store != nil {
+		if err := cm.restoreState(ctx); err != nil {
+			return fmt.Errorf("restore state failed: %w", err)
+		}
+	}
+
 	// register functions
+	cm.runCtx = ctx
 	for idx := range cm.jobs {
 		j := cm.jobs[idx]
+		cronFnCtx := cm.newCronFunc(idx)
+		schedFn := cronFnCtx
+		if cm.dryRun {
+			schedFn = cm.newDryRunFunc(idx)
+		}
+		if j.isMaintenance && cm.maintenanceWindow != nil {
+			schedFn = cm.newMaintenanceWindowFunc(idx, schedFn)
+		}
+		if cm.leader != nil {
+			schedFn = cm.newLeaderFunc(idx, schedFn)
+		}
+		if cm.sharding != nil {
+			schedFn = cm.newShardFunc(idx, schedFn)
+		}
+		schedFn = cm.newDrainFunc(idx, schedFn)
+		if _, ok := j.sched.(atSchedule); ok {
+			schedFn = cm.newAtFunc(idx, schedFn)
+		}
 
-		// create main job function
-		cronFnCtx := func(ctx context.Context) error {
-			// set middleware to func
-			f := j.fn
-			for i := len(cm.middleware) - 1; i >= 0; i-- {
-				f = cm.middleware[i](f)
-			}
+		// check for disabled schedule. save cronFn to job for manual run
+		if !j.schedule.IsActive() {
+			cm.updateID(idx, cron.EntryID(idx*-1), cronFnCtx, schedFn) // set fake id
+			cm.updateState(idx, j.schedule.inactiveState(), nil)
+			continue
+		}
 
-			// set context
-			ctx = NewNameContext(ctx, j.name)
-			ctx = NewMaintenanceContext(ctx, j.isMaintenance)
+		// set functions and register main functions in cron library
+		cm.updateID(idx, j.id, cronFnCtx, schedFn)
+		cm.schedule(idx)
+	}
 
-			// invoke main func with middleware
-			cm.updateState(idx, stateRunning, nil)
-			err := f(ctx)
-			cm.updateState(idx, stateIdle, err)
+	// run main cron process in its own go routine
+	cm.background, cm.stopBackground = context.WithCancel(ctx)
+	cm.startedAt = cm.clock.Now()
+	if cm.manualTicker {
+		cm.lastTick = cm.clock.Now()
+	} else {
+		cm.cron.Start()
+	}
+	cm.startPool(cm.background)
+	cm.catchUp(ctx)
+	cm.runOnStart(ctx)
+	cm.startWatchdog(cm.background)
 
-			return err
+	// stop scheduling on ctx cancellation, running jobs observe cancelled ctx by themselves
+	if ctx.Done() != nil {
+		c := cm.cron
+		go func() {
+			<-ctx.Done()
+			c.Stop()
+		}()
+	}
+
+	return nil
+}
+
+// runOnStart runs jobs with RunOnStart option in separate goroutines or worker pool.
+// Disabled and auto-disabled jobs are not run.
+func (cm *Manager) runOnStart(ctx context.Context) {
+	for idx, j := range cm.jobs {
+		if j.runOnStart && j.schedule.IsActive() && !cm.isAutoDisabled(j) {
+			cm.runScheduled(ctx, idx)
 		}
-		// check for disabled schedule. save cronFn to job for manual run
-		if !j.schedule.IsActive() {
-			cm.updateID(idx, cron.EntryID(idx*-1), cronFnCtx) // set fake id
-			cm.updateState(idx, stateDisabled, nil)
+	}
+}
+
+// Tick synchronously runs all jobs with activations after previous tick (or Run) and up to at.
+// Each job runs once per tick. It is used with WithManualTicker option in tests.
+func (cm *Manager) Tick(ctx context.Context, at time.Time) error {
+	var errs []error
+	for _, j := range cm.jobs {
+		if j.sched == nil || cm.isAutoDisabled(j) || cm.Paused() {
 			continue
 		}
 
-		// register main functions in cron library
-		id, err := cm.cron.AddFunc(j.schedule.String(), func() { _ = cronFnCtx(ctx) })
-		if err != nil {
-			return fmt.Errorf("add cron=%v failed: %w", j.name, err)
+		if next := j.sched.Next(cm.lastTick); next.IsZero() || next.After(at) {
+			continue
 		}
 
-		// set ID
-		cm.updateID(idx, id, cronFnCtx)
+		if err := j.schedFn(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", j.name, err))
+		}
 	}
+	cm.lastTick = at
 
-	// run main cron process in its own go routine
-	cm.cron.Start()
+	return errors.Join(errs...)
+}
 
-	return nil
+// newCronFunc returns main job function with middleware, context and state tracking.
+func (cm *Manager) newCronFunc(idx int) Func {
+	j := cm.jobs[idx]
+
+	return func(ctx context.Context) error {
+		// set middleware to func
+		f := j.fn
+		for i := len(j.middleware) - 1; i >= 0; i-- {
+			f = j.middleware[i](f)
+		}
+		for i := len(cm.middleware) - 1; i >= 0; i-- {
+			f = cm.middleware[i](f)
+		}
+
+		// set context
+		startedAt := cm.clock.Now()
+		cm.muState.RLock()
+		schedule := j.schedule
+		cm.muState.RUnlock()
+
+		ctx = NewNameContext(ctx, j.name)
+		ctx = NewScheduleContext(ctx, schedule)
+		ctx = NewMaintenanceContext(ctx, j.isMaintenance)
+		ctx = NewManagerNameContext(ctx, cm.name)
+		ctx = newClockContext(ctx, cm.clock)
+		ctx = NewLastSuccessContext(ctx, cm.lastSuccess(idx))
+		ctx = NewStartTimeContext(ctx, startedAt)
+		ctx = NewMaintenanceWaitContext(ctx, 0)
+		ctx = context.WithValue(ctx, stateFuncKey, func(state cronState) { cm.updateState(idx, state, nil) })
+		ctx = context.WithValue(ctx, logRingKey, func(n int) *logRing { return cm.logRing(idx, n) })
+		ctx = context.WithValue(ctx, runMessageKey, func(msg string) { cm.setMessage(idx, msg) })
+		ctx = context.WithValue(ctx, breakerKey, func(state string) { cm.setBreaker(idx, state) })
+		ctx = context.WithValue(ctx, metricsAppKey, func(app string) { cm.setMetricsApp(idx, app) })
+		ctx = context.WithValue(ctx, resourcesKey, cm.resource)
+		if cm.logger != nil {
+			ctx = context.WithValue(ctx, loggerKey, cm.logger)
+		}
+		for _, fn := range j.contexts {
+			ctx = fn(ctx)
+		}
+
+		ctx, run := cm.trackRun(ctx, idx)
+		defer cm.untrackRun(run)
+
+		// invoke main func with middleware
+		cm.updateState(idx, stateRunning, nil)
+		if j.maxDuration > 0 {
+			t := time.AfterFunc(j.maxDuration, func() { cm.markOverrun(idx) })
+			defer t.Stop()
+		}
+
+		err := cm.safeCall(ctx, idx, startedAt, f)
+		if cause := context.Cause(ctx); errors.Is(err, context.Canceled) && errors.Is(cause, ErrCancelled) {
+			err = cause
+		}
+		cm.updateState(idx, stateIdle, err)
+		cm.saveState(ctx)
+		cm.recordRun(ctx, j.name, startedAt, err)
+		cm.checkFailures(ctx, idx, err)
+		cm.pushMetrics(ctx)
+
+		return err
+	}
+}
+
+// safeCall calls f and records panic escaped all middleware in job state, so UI shows which job died.
+// Panic is raised again unless WithRecoverPanics is set.
+func (cm *Manager) safeCall(ctx context.Context, idx int, startedAt time.Time, f Func) (err error) {
+	defer func() {
+		rec := recover()
+		if rec == nil {
+			return
+		}
+
+		pe := newPanicError(rec)
+		if cm.recoverPanics {
+			err = pe
+			return
+		}
+
+		cm.updateState(idx, stateIdle, pe)
+		cm.saveState(ctx)
+		cm.recordRun(ctx, cm.jobs[idx].name, startedAt, pe)
+		panic(rec)
+	}()
+
+	return f(ctx)
 }
 
-// Stop stops current cron instance.
+// newDryRunFunc returns job function which only logs run via WithManagerLogger and sets dry-run state.
+func (cm *Manager) newDryRunFunc(idx int) Func {
+	name := cm.jobs[idx].name
+
+	return func(ctx context.Context) error {
+		if cm.logger != nil {
+			cm.logger.Print(ctx, "cron job would run", "job", name)
+		}
+		cm.updateState(idx, stateDryRun, nil)
+
+		return nil
+	}
+}
+
+// Stop stops current cron instance with watchdog, worker pool and deferred runs. Returned context is done
+// when scheduled runs in progress are finished.
 func (cm *Manager) Stop() context.Context {
 	if cm.cron == nil {
 		return context.Background()
 	}
 
-	return cm.cron.Stop()
+	ctx := cm.cron.Stop()
+	if cm.stopBackground != nil {
+		cm.stopBackground()
+	}
+	cm.flushMetrics(ctx)
+
+	return ctx
+}
+
+// Reset stops the scheduler, watchdog and worker pool, waits for runs in progress (including catch-up
+// and manual runs) and removes all jobs and their states. Queued and deferred runs are dropped.
+// Manager options and middleware are kept, so jobs can be added and run again. It is intended for tests.
+func (cm *Manager) Reset() {
+	<-cm.Stop().Done()
+	cm.wgBackground.Wait()
+	cm.dropQueued()
+	for _, r := range cm.activeRuns() {
+		<-r.done
+	}
+
+	cm.muResources.Lock()
+	cm.resources = nil
+	cm.muResources.Unlock()
+
+	cm.muState.Lock()
+	defer cm.muState.Unlock()
+
+	cm.cron = cm.newCron()
+	cm.jobs, cm.runCtx, cm.background, cm.stopBackground = nil, nil, nil, nil
+	cm.history.reset()
+	cm.lastTick, cm.startedAt = time.Time{}, time.Time{}
+	cm.paused = false
+	cm.setDraining(false)
 }
 
 // updateState set.
 func (cm *Manager) updateState(idx int, state cronState, err error) {
+	var changed bool
+	defer func() {
+		if changed {
+			cm.notifyState(idx)
+		}
+	}()
+
 	cm.muState.Lock()
 	defer cm.muState.Unlock()
 
 	last := cm.jobs[idx].last
+	prev := last.state
+	now := cm.clock.Now()
+
+	// set dur when state changed from running to idle, waiting in middleware is excluded.
+	switch {
+	case state == stateRunning && last.resume != "":
+		// resume after waiting: keep run start and overrun or stuck mark
+		state, last.waited, last.resume = last.resume, last.waited+now.Sub(last.waitedAt), ""
+	case state == stateRunning:
+		last.startedAt, last.lastRun, last.message, last.waited = now, now, "", 0
+	case (state == stateWaiting || state == stateQueued) && prev.isActive():
+		last.resume, last.waitedAt = prev, now
+	case (prev.isActive() || last.resume != "") && state == stateIdle:
+		if last.resume != "" {
+			last.waited, last.resume = last.waited+now.Sub(last.waitedAt), ""
+		}
+		last.duration = now.Sub(last.startedAt) - last.waited
 
-	// set dur when state changed from running to idle.
-	if last.state == stateRunning && state == stateIdle {
-		last.duration = time.Since(last.updatedAt)
+		// save last successful run and failures streak
+		switch {
+		case err == nil:
+			last.successAt, last.failures, last.failErr = now, 0, nil
+		case !errors.Is(err, ErrSkipped):
+			last.failures, last.failErr = last.failures+1, err
+		}
 	}
 
 	// do not set idle state if skipped
 	last.state, last.err = state, err
-	last.updatedAt = time.Now()
+	last.updatedAt = now
+
+	// save panic stack separately from error
+	last.stack = nil
+	var pe *PanicError
+	if errors.As(err, &pe) {
+		last.stack = pe.Stack
+	}
 
 	// check for Skipped Err
+	last.reason, last.kind = "", ""
 	if errors.Is(err, ErrSkipped) {
 		last.state, last.err = stateSkipped, nil
+
+		var se SkipError
+		if errors.As(err, &se) {
+			last.reason, last.kind = se.Reason, se.Kind
+		}
+	}
+
+	// keep failed state until successful run
+	if cm.failedState && last.failures > 0 && (last.state == stateIdle || last.state == stateSkipped) {
+		last.state = stateFailed
+		if last.err == nil {
+			last.err = last.failErr
+		}
+	}
+
+	// keep disabled state after runs until Enable
+	if j := cm.jobs[idx]; j.autoDisabled && (last.state == stateIdle || last.state == stateSkipped || last.state == stateFailed) {
+		last.state, last.reason, last.kind = stateDisabled, j.disabledReason(), SkipDisabled
 	}
 
 	// fix state
 	cm.jobs[idx].last = last
+	changed = prev != last.state
+}
+
+// skipRun records skipped run outside of job run path, e.g. by drain or leader check. If the job is running,
+// its state is kept and only skip reason is recorded.
+func (cm *Manager) skipRun(idx int, err error) {
+	if !cm.isRunning(idx) {
+		cm.updateState(idx, stateIdle, err)
+		return
+	}
+
+	var se SkipError
+	errors.As(err, &se)
+
+	cm.muState.Lock()
+	cm.jobs[idx].last.reason, cm.jobs[idx].last.kind = se.Reason, se.Kind
+	cm.muState.Unlock()
+}
+
+// OnStateChange adds handler called on every job state change, e.g. to stream changes to a message bus.
+// Handlers are called synchronously in job goroutine outside of Manager locks.
+func (cm *Manager) OnStateChange(fn func(State)) {
+	cm.muState.Lock()
+	defer cm.muState.Unlock()
+
+	cm.stateHandlers = append(cm.stateHandlers, fn)
+}
+
+// notifyState calls OnStateChange handlers with current job state.
+func (cm *Manager) notifyState(idx int) {
+	cm.muState.RLock()
+	handlers, id := cm.stateHandlers, cm.jobs[idx].id
+	cm.muState.RUnlock()
+	if len(handlers) == 0 {
+		return
+	}
+
+	e := cm.cron.Entry(id)
+
+	cm.muState.RLock()
+	s := cm.jobState(cm.jobs[idx], e, cm.clock.Now())
+	cm.muState.RUnlock()
+
+	for _, fn := range handlers {
+		fn(s)
+	}
+}
+
+// Running returns names of jobs in progress: running, overrun, waiting for a lock or queued.
+// It is based on running invocations, not on job states, so it includes jobs until their runs return.
+func (cm *Manager) Running() []string {
+	running := make(map[int]bool)
+	for _, r := range cm.activeRuns() {
+		running[r.idx] = true
+	}
+
+	var names []string
+	for i, j := range cm.jobs {
+		if running[i] {
+			names = append(names, j.name)
+		}
+	}
+
+	return names
+}
+
+// lastSuccess returns last successful run finish time.
+func (cm *Manager) lastSuccess(idx int) time.Time {
+	cm.muState.RLock()
+	defer cm.muState.RUnlock()
+
+	return cm.jobs[idx].last.successAt
+}
+
+// markOverrun sets overrun state for running job. Job continues running.
+func (cm *Manager) markOverrun(idx int) {
+	cm.muState.Lock()
+	last := &cm.jobs[idx].last
+	switch {
+	case last.state == stateRunning:
+		last.state = stateOverrun
+	case last.resume == stateRunning:
+		// job is waiting in middleware, mark is restored on resume
+		last.resume = stateOverrun
+	default:
+		cm.muState.Unlock()
+		return
+	}
+
+	cm.jobs[idx].last.updatedAt = cm.clock.Now()
+	cm.muState.Unlock()
+
+	metricOverrun().WithLabelValues(cm.metricsApp(idx), cm.jobs[idx].name).Inc()
+	cm.notifyState(idx)
 }
 
 // updateID sets cron.EntryID for job.
-func (cm *Manager) updateID(idx int, id cron.EntryID, funcJob Func) {
+func (cm *Manager) updateID(idx int, id cron.EntryID, funcJob, schedFn Func) {
 	cm.muState.Lock()
 	defer cm.muState.Unlock()
 
 	cm.jobs[idx].id = id
 	cm.jobs[idx].cronFn = funcJob
+	cm.jobs[idx].schedFn = schedFn
 }
 
 // Use adds middleware for cron job.
@@ -228,10 +942,69 @@ func (cm *Manager) Use(m ...MiddlewareFunc) {
 	cm.middleware = append(cm.middleware, m...)
 }
 
+// Middleware returns names of Manager's middleware in order, e.g. "WithRecover", "WithSentry".
+// Middleware wrapped by another one (e.g. Unless) is reported by the wrapper name,
+// middleware with options by the base name, e.g. WithSentryOptions as "WithSentry".
+func (cm *Manager) Middleware() []string {
+	names := make([]string, len(cm.middleware))
+	for i, m := range cm.middleware {
+		names[i] = middlewareName(m)
+	}
+
+	return names
+}
+
+// middlewareName returns function name of middleware constructor without package path.
+func middlewareName(m MiddlewareFunc) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(m).Pointer())
+	if fn == nil {
+		return ""
+	}
+
+	// github.com/vmkteam/cron.WithRecover.func1 or cron.WithRecover.1 (inlined) -> WithRecover,
+	// cron.(*T).Middleware.func1 -> (*T).Middleware
+	name := fn.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	_, name, _ = strings.Cut(name, ".")
+	parts := strings.SplitN(name, ".", 3)
+	if strings.HasPrefix(parts[0], "(") && len(parts) > 1 {
+		return parts[0] + "." + parts[1]
+	}
+	if base, ok := middlewareBase[parts[0]]; ok {
+		return base
+	}
+
+	return parts[0]
+}
+
+// middlewareBase maps middleware with options to base middleware built by them, see Manager.Middleware.
+var middlewareBase = map[string]string{
+	"WithSentryOptions":      "WithSentry",
+	"WithMaintenanceOptions": "WithMaintenance",
+	"WithResourceOptions":    "WithResource",
+	"WithChaosOptions":       "WithChaos",
+}
+
+// isSentryMiddleware checks middleware name for WithSentry and its variants.
+func isSentryMiddleware(name string) bool {
+	return strings.HasPrefix(name, "WithSentry")
+}
+
+// checkMiddleware logs conflicting middleware via WithManagerLogger.
+func (cm *Manager) checkMiddleware(ctx context.Context) {
+	names := cm.Middleware()
+	if cm.logger != nil && slices.Contains(names, "WithRecover") && slices.ContainsFunc(names, isSentryMiddleware) {
+		cm.logger.Error(ctx, "cron middleware conflict: WithSentry already recovers panics, remove WithRecover", "middleware", names)
+	}
+}
+
 // newJob returns new job.
-func newJob(name string, schedule Schedule, fn Func, isMaintenance bool) job {
-	return job{
+func newJob(name string, schedule Schedule, fn Func, isMaintenance bool, opts ...JobOption) *job {
+	j := &job{
 		name:          name,
+		key:           name,
 		schedule:      schedule,
 		fn:            fn,
 		isMaintenance: isMaintenance,
@@ -239,6 +1012,85 @@ func newJob(name string, schedule Schedule, fn Func, isMaintenance bool) job {
 			state: stateIdle,
 		},
 	}
+
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	return j
+}
+
+// jobIndex returns job index by key or name or -1.
+func (cm *Manager) jobIndex(id string) int {
+	return slices.IndexFunc(cm.jobs, func(j *job) bool { return j.match(id) })
+}
+
+// match checks if id is job key or name (case-insensitive).
+func (j *job) match(id string) bool {
+	return j.key == id || strings.EqualFold(j.name, id)
+}
+
+// Key sets stable job key for links and external dashboards. Default key is job name.
+// Unlike State.ID, key doesn't depend on registration order.
+func Key(key string) JobOption {
+	return func(j *job) {
+		j.key = key
+	}
+}
+
+// Description sets job description shown on job page and in UI tooltip.
+func Description(text string) JobOption {
+	return func(j *job) {
+		j.description = text
+	}
+}
+
+// Tags sets job tags shown on job page, e.g. team or subsystem.
+func Tags(tags ...string) JobOption {
+	return func(j *job) {
+		j.tags = append(j.tags, tags...)
+	}
+}
+
+// MaxDuration marks running job as overrun after d in state and metrics (app_cron_overrun_total,
+// with app label of WithMetrics).
+// Unlike context timeout, it doesn't stop the job: use it for jobs which ignore context cancellation.
+func MaxDuration(d time.Duration) JobOption {
+	return func(j *job) {
+		j.maxDuration = d
+	}
+}
+
+// ShutdownGrace sets job grace period for Shutdown: the job keeps running up to d after shutdown has begun
+// instead of being cancelled immediately, even after Shutdown context is done. Job context is cancelled when
+// grace period expires.
+func ShutdownGrace(d time.Duration) JobOption {
+	return func(j *job) {
+		j.shutdownGrace = d
+	}
+}
+
+// RunOnStart runs the job once on Manager.Run in addition to its schedule, e.g. to warm caches.
+// Run goes through scheduled run path: middleware, state tracking, drain and leader checks.
+// Jobs with disabled schedule or disabled by DisableAfterFailures are not run.
+func RunOnStart() JobOption {
+	return func(j *job) {
+		j.runOnStart = true
+	}
+}
+
+// JobMiddleware adds middleware for the job only. It is applied after Manager's middleware.
+func JobMiddleware(m ...MiddlewareFunc) JobOption {
+	return func(j *job) {
+		j.middleware = append(j.middleware, m...)
+	}
+}
+
+// JobContext derives job run context with fn, e.g. to set per-job flags for middleware.
+func JobContext(fn func(ctx context.Context) context.Context) JobOption {
+	return func(j *job) {
+		j.contexts = append(j.contexts, fn)
+	}
 }
 
 func NewMaintenanceContext(ctx context.Context, isMaintenance bool) context.Context {
@@ -264,3 +1116,83 @@ func NameFromContext(ctx context.Context) string {
 
 	return ""
 }
+
+// NewScheduleContext creates new context with job schedule.
+func NewScheduleContext(ctx context.Context, schedule Schedule) context.Context {
+	return context.WithValue(ctx, scheduleKey, schedule)
+}
+
+// ScheduleFromContext returns job schedule as it was added, e.g. "@every 5m" or "H 3 * * *".
+// Resolved spec of H schedules is available via Manager.State.
+func ScheduleFromContext(ctx context.Context) Schedule {
+	if v, ok := ctx.Value(scheduleKey).(Schedule); ok {
+		return v
+	}
+
+	return ""
+}
+
+// NewManagerNameContext creates new context with manager name.
+func NewManagerNameContext(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, managerKey, name)
+}
+
+// ManagerNameFromContext returns manager name set by WithName option.
+func ManagerNameFromContext(ctx context.Context) string {
+	if v, ok := ctx.Value(managerKey).(string); ok {
+		return v
+	}
+
+	return ""
+}
+
+// NewStartTimeContext creates new context with current run start time.
+func NewStartTimeContext(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, startTimeKey, t)
+}
+
+// StartTimeFromContext returns current run start time or zero time.
+func StartTimeFromContext(ctx context.Context) time.Time {
+	if v, ok := ctx.Value(startTimeKey).(time.Time); ok {
+		return v
+	}
+
+	return time.Time{}
+}
+
+// setState updates job state from middleware, e.g. to show that job is waiting for a lock.
+func setState(ctx context.Context, state cronState) {
+	if fn, ok := ctx.Value(stateFuncKey).(func(cronState)); ok {
+		fn(state)
+	}
+}
+
+// SetRunMessage sets run summary shown in State.LastMessage and UI, e.g. "processed 1423 rows".
+// Message is cleared at the start of each run.
+func SetRunMessage(ctx context.Context, msg string) {
+	if fn, ok := ctx.Value(runMessageKey).(func(string)); ok {
+		fn(msg)
+	}
+}
+
+// setMessage sets job run message.
+func (cm *Manager) setMessage(idx int, msg string) {
+	cm.muState.Lock()
+	defer cm.muState.Unlock()
+
+	cm.jobs[idx].last.message = msg
+}
+
+// NewLastSuccessContext creates new context with last successful run finish time.
+func NewLastSuccessContext(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, lastSuccessKey, t)
+}
+
+// LastSuccessFromContext returns last successful run finish time of the job or zero time.
+func LastSuccessFromContext(ctx context.Context) time.Time {
+	if v, ok := ctx.Value(lastSuccessKey).(time.Time); ok {
+		return v
+	}
+
+	return time.Time{}
+}
@@ -1,13 +1,27 @@
 package cron
 
 import (
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"html/template"
 	"log"
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/robfig/cron/v3"
 	. "github.com/smartystreets/goconvey/convey"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func newCronFunc(msg string) Func {
@@ -17,6 +31,52 @@ func newCronFunc(msg string) Func {
 	}
 }
 
+// watchStates returns func which waits until the job gets state, states are tracked via OnStateChange.
+func watchStates(m *Manager) func(name, state string) {
+	var mu sync.Mutex
+	seen := map[string]chan struct{}{}
+	get := func(key string) chan struct{} {
+		mu.Lock()
+		defer mu.Unlock()
+		if seen[key] == nil {
+			seen[key] = make(chan struct{})
+		}
+		return seen[key]
+	}
+
+	m.OnStateChange(func(s State) {
+		ch := get(s.Name + ":" + s.LastState)
+		mu.Lock()
+		defer mu.Unlock()
+		select {
+		case <-ch:
+		default:
+			close(ch)
+		}
+	})
+
+	return func(name, state string) { <-get(name + ":" + state) }
+}
+
+// manualRun starts manual run in background, returned channel receives its result.
+func manualRun(ctx context.Context, m *Manager, name string) <-chan error {
+	done := make(chan error, 1)
+	go func() { done <- m.ManualRun(ctx, name) }()
+	return done
+}
+
+// fastParser parses "@every d" schedules without rounding to seconds, so scheduler tests don't wait for long.
+type fastParser struct{}
+
+func (fastParser) Parse(spec string) (cron.Schedule, error) {
+	d, err := time.ParseDuration(strings.TrimPrefix(spec, "@every "))
+	return fastSchedule(d), err
+}
+
+type fastSchedule time.Duration
+
+func (s fastSchedule) Next(t time.Time) time.Time { return t.Add(time.Duration(s)) }
+
 func TestManager_Validate(t *testing.T) {
 	Convey("Test validate function", t, func() {
 		m := NewManager()
@@ -71,3 +131,842 @@ func TestManager_Run(t *testing.T) {
 		})
 	})
 }
+
+func TestManager_Panic(t *testing.T) {
+	Convey("Test panics escaped middleware", t, func() {
+		boom := func(context.Context) error { panic("boom") }
+
+		Convey("Test panic is recorded and raised again", func() {
+			m := NewManager()
+			m.AddFunc("f1", "", boom)
+			So(m.Run(t.Context()), ShouldBeNil)
+
+			So(func() { _ = m.ManualRun(t.Context(), "f1") }, ShouldPanicWith, "boom")
+			st := m.State()[0]
+			So(st.LastState, ShouldEqual, "idle")
+			So(st.LastErr.Error(), ShouldEqual, "panic: boom")
+			So(st.LastStack, ShouldNotBeEmpty)
+			So(m.Running(), ShouldBeEmpty)
+		})
+
+		Convey("Test panic is recovered", func() {
+			m := NewManager(WithRecoverPanics())
+			m.AddFunc("f1", "", boom)
+			So(m.Run(t.Context()), ShouldBeNil)
+
+			var pe *PanicError
+			So(errors.As(m.ManualRun(t.Context(), "f1"), &pe), ShouldBeTrue)
+			So(m.State()[0].Failures, ShouldEqual, 1)
+		})
+	})
+}
+
+func TestManager_RunCancel(t *testing.T) {
+	Convey("Test run with cancelled context", t, func() {
+		ctx, cancel := context.WithCancel(t.Context())
+		m := NewManager(WithScheduleParser(fastParser{}))
+
+		var runs atomic.Int32
+		ran := make(chan struct{}, 1)
+		m.AddFunc("f1", "@every 10ms", func(ctx context.Context) error {
+			runs.Add(1)
+			select {
+			case ran <- struct{}{}:
+			default:
+			}
+			return nil
+		})
+
+		So(m.Run(ctx), ShouldBeNil)
+		<-ran
+
+		// scheduler is stopped asynchronously, then there are no runs for 10 intervals
+		cancel()
+		time.Sleep(20 * time.Millisecond)
+		n := runs.Load()
+
+		time.Sleep(100 * time.Millisecond)
+		So(runs.Load(), ShouldEqual, n)
+	})
+}
+
+func TestManager_MaxDuration(t *testing.T) {
+	Convey("Test max duration overrun", t, func() {
+		m := NewManager()
+		m.Use(WithMetrics("test-overrun"))
+		overrun := metricDelta("app_cron_overrun_total", map[string]string{"app": "test-overrun", "cron": "f1"})
+		m.AddFunc("f1", "", func(ctx context.Context) error {
+			time.Sleep(300 * time.Millisecond)
+			return nil
+		}, MaxDuration(100*time.Millisecond))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		go func() { _ = m.ManualRun(t.Context(), "f1") }()
+		time.Sleep(200 * time.Millisecond)
+		So(m.State()[0].LastState, ShouldEqual, "overrun")
+		So(overrun(), ShouldEqual, 1)
+
+		time.Sleep(200 * time.Millisecond)
+		st := m.State()[0]
+		So(st.LastState, ShouldEqual, "idle")
+		So(st.LastDuration, ShouldBeGreaterThanOrEqualTo, 300*time.Millisecond)
+	})
+}
+
+func TestManager_ResumeState(t *testing.T) {
+	Convey("Test waiting in middleware keeps run start and overrun mark", t, func() {
+		start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		clock := &testClock{now: start}
+		m := NewManager(WithClock(clock))
+
+		var st State
+		wait := func(next Func) Func {
+			return func(ctx context.Context) error {
+				clock.Add(time.Minute)
+				setState(ctx, stateWaiting)
+				clock.Add(2 * time.Minute)
+				m.markOverrun(0)
+				setState(ctx, stateRunning)
+				return next(ctx)
+			}
+		}
+		m.AddFunc("f1", "", func(context.Context) error {
+			st = m.State()[0]
+			clock.Add(time.Minute)
+			return nil
+		}, JobMiddleware(wait))
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		So(st.LastState, ShouldEqual, "overrun")
+		So(st.LastRun, ShouldEqual, start)
+
+		st = m.State()[0]
+		So(st.LastState, ShouldEqual, "idle")
+		So(st.LastDuration, ShouldEqual, 2*time.Minute)
+	})
+}
+
+func TestManager_Healthy(t *testing.T) {
+	Convey("Test healthy", t, func() {
+		m := NewManager(WithHealthThreshold(2))
+		m.AddFunc("f1", "", func(ctx context.Context) error { return errors.New("failed") })
+		m.AddFunc("f2", "", newCronFunc("f2"))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		_ = m.ManualRun(t.Context(), "f1")
+		ok, names := m.Healthy()
+		So(ok, ShouldBeTrue)
+		So(names, ShouldBeEmpty)
+
+		_ = m.ManualRun(t.Context(), "f1")
+		_ = m.ManualRun(t.Context(), "f2")
+		ok, names = m.Healthy()
+		So(ok, ShouldBeFalse)
+		So(names, ShouldResemble, []string{"f1"})
+		So(m.State()[0].Failures, ShouldEqual, 2)
+	})
+}
+
+func TestManager_Summary(t *testing.T) {
+	Convey("Test summary counts", t, func() {
+		m := NewManager()
+		m.AddFunc("f1", "0 0 * * *", func(context.Context) error { return errors.New("failed") })
+		m.AddFunc("f2", "", newCronFunc("f2"))
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+		_ = m.ManualRun(t.Context(), "f1")
+
+		w := httptest.NewRecorder()
+		m.Handler(w, httptest.NewRequest(http.MethodGet, "/?summary=1", nil))
+		So(w.Header().Get("Content-Type"), ShouldEqual, "application/json")
+
+		var sm Summary
+		So(json.NewDecoder(w.Body).Decode(&sm), ShouldBeNil)
+		So(sm, ShouldResemble, Summary{Total: 2, Disabled: 1, Errored: 1})
+	})
+}
+
+func TestManager_FailedState(t *testing.T) {
+	Convey("Test failed state until successful run", t, func() {
+		m := NewManager(WithFailedState())
+		var err error
+		m.AddFunc("f1", "", func(context.Context) error { return err })
+		m.AddFunc("f2", "", func(context.Context) error { return newSkipError(SkipGuard, "down") })
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		err = errors.New("failed")
+		_ = m.ManualRun(t.Context(), "f1")
+		So(m.State()[0].LastState, ShouldEqual, "failed")
+		So(m.State().LogValue().String(), ShouldContainSubstring, "state=failed err=failed")
+
+		err = newSkipError(SkipGuard, "down")
+		_ = m.ManualRun(t.Context(), "f1")
+		So(m.State()[0].LastState, ShouldEqual, "failed")
+		So(m.State()[0].SkipReason, ShouldEqual, "down")
+		So(m.State().LogValue().String(), ShouldContainSubstring, "state=failed err=failed")
+
+		err = nil
+		_ = m.ManualRun(t.Context(), "f1")
+		So(m.State()[0].LastState, ShouldEqual, "idle")
+		So(m.State()[0].LastErr, ShouldBeNil)
+
+		_ = m.ManualRun(t.Context(), "f2")
+		So(m.State()[1].LastState, ShouldEqual, "skipped")
+	})
+}
+
+func TestSetRunMessage(t *testing.T) {
+	Convey("Test run message", t, func() {
+		m := NewManager()
+		var rows int
+		m.AddFunc("f1", "", func(ctx context.Context) error {
+			if rows > 0 {
+				SetRunMessage(ctx, fmt.Sprintf("processed %d rows", rows))
+			}
+			return nil
+		})
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		rows = 1423
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		So(m.State()[0].LastMessage, ShouldEqual, "processed 1423 rows")
+
+		w, r := httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", "text/html")
+		m.Handler(w, r)
+		So(w.Body.String(), ShouldContainSubstring, "processed 1423 rows")
+
+		rows = 0
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		So(m.State()[0].LastMessage, ShouldBeEmpty)
+	})
+}
+
+func TestManager_Schedule(t *testing.T) {
+	Convey("Test parsed schedule", t, func() {
+		m := NewManager()
+		m.AddFunc("f1", "0 3 * * *", newCronFunc("f1"))
+		m.AddFunc("f2", "disabled", newCronFunc("f2"))
+
+		sch, err := m.Schedule("f1")
+		So(err, ShouldBeNil)
+		now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.Local)
+		So(sch.Next(now), ShouldEqual, time.Date(2025, 1, 2, 3, 0, 0, 0, time.Local))
+
+		sch, err = m.Schedule("f2")
+		So(err, ShouldBeNil)
+		So(sch, ShouldBeNil)
+
+		_, err = m.Schedule("f3")
+		So(err, ShouldEqual, ErrNotFound)
+	})
+}
+
+func TestManager_TextSchedule(t *testing.T) {
+	Convey("Test text schedule", t, func() {
+		clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 30, 0, time.UTC)}
+		m := NewManager(WithClock(clock), WithManualTicker())
+		m.AddFunc("f1", "* * * * *", newCronFunc("f1"))
+		m.AddMaintenanceFunc("f2", "disabled", newCronFunc("f2"))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		var buf strings.Builder
+		m.TextSchedulePlain(&buf)
+		So(buf.String(), ShouldEqual, ""+
+			"cron                   schedule   next   duration  state\n"+
+			"cron=f1                * * * * *  never  -         idle\n"+
+			"cron=f2 (maintenance)  disabled   never  -         disabled\n")
+
+		buf.Reset()
+		m.TextSchedule(&buf)
+		So(buf.String(), ShouldContainSubstring, "|")
+	})
+}
+
+func TestManager_JobHandler(t *testing.T) {
+	Convey("Test job details page", t, func() {
+		clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 30, 0, time.UTC)}
+		m := NewManager(WithClock(clock))
+		m.Use(WithRecover())
+		m.AddFunc("f1", "* * * * *", func(context.Context) error { panic("boom") },
+			Description("imports orders"), Tags("billing", "nightly"))
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+		So(m.ManualRun(t.Context(), "f1"), ShouldNotBeNil)
+
+		get := func(url, accept string) *httptest.ResponseRecorder {
+			r := httptest.NewRequest(http.MethodGet, url, nil)
+			r.Header.Set("Accept", accept)
+			w := httptest.NewRecorder()
+			m.Handler(w, r)
+			return w
+		}
+
+		Convey("Test json", func() {
+			w := get("/?job=f1", "application/json")
+			So(w.Code, ShouldEqual, http.StatusOK)
+
+			var d struct {
+				Name        string
+				Description string
+				Tags        []string
+				LastStack   string
+				History     []RunRecord
+				NextRuns    []time.Time
+			}
+			So(json.NewDecoder(w.Body).Decode(&d), ShouldBeNil)
+			So(d.Name, ShouldEqual, "f1")
+			So(d.Description, ShouldEqual, "imports orders")
+			So(d.Tags, ShouldResemble, []string{"billing", "nightly"})
+			So(d.History, ShouldHaveLength, 1)
+			So(d.LastStack, ShouldNotBeEmpty)
+			So(d.NextRuns, ShouldHaveLength, 10)
+			So(d.NextRuns[0], ShouldEqual, time.Date(2025, 1, 1, 12, 1, 0, 0, time.UTC))
+		})
+
+		Convey("Test html", func() {
+			w := get("/?job=f1", "text/html")
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(w.Body.String(), ShouldContainSubstring, "panic: boom")
+			So(w.Body.String(), ShouldContainSubstring, "Stack trace")
+			So(w.Body.String(), ShouldContainSubstring, "<p>imports orders</p>")
+			So(w.Body.String(), ShouldContainSubstring, "<td>billing, nightly</td>")
+
+			w = get("/", "text/html")
+			So(w.Body.String(), ShouldContainSubstring, `href="?job=f1"`)
+		})
+
+		Convey("Test unknown job", func() {
+			So(get("/?job=f2", "text/html").Code, ShouldEqual, http.StatusNotFound)
+		})
+	})
+}
+
+func TestManager_ManualRunHandler(t *testing.T) {
+	Convey("Test manual run from UI", t, func() {
+		started := make(chan string, 1)
+		newManager := func(opts ...Option) *Manager {
+			m := NewManager(opts...)
+			m.AddFunc("f1", "", func(ctx context.Context) error {
+				started <- NameFromContext(ctx)
+				return nil
+			})
+			m.AddFunc("f2", "", func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			})
+			So(m.Run(t.Context()), ShouldBeNil)
+			return m
+		}
+		post := func(m *Manager, url, token, cookie string) *httptest.ResponseRecorder {
+			form := neturl.Values{"start": {"f1"}, "csrf": {token}}
+			r := httptest.NewRequest(http.MethodPost, url, strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if cookie != "" {
+				r.AddCookie(&http.Cookie{Name: csrfCookie, Value: cookie})
+			}
+			w := httptest.NewRecorder()
+			m.Handler(w, r)
+			return w
+		}
+
+		Convey("Test POST with csrf token", func() {
+			m := newManager()
+
+			r := httptest.NewRequest(http.MethodGet, "/debug/cron", nil)
+			r.Header.Set("Accept", "text/html")
+			w := httptest.NewRecorder()
+			m.Handler(w, r)
+
+			cookies := w.Result().Cookies()
+			So(cookies, ShouldHaveLength, 1)
+			token := cookies[0].Value
+			So(w.Body.String(), ShouldContainSubstring, `name="csrf" value="`+token+`"`)
+			So(w.Body.String(), ShouldContainSubstring, `<button name="start" value="f1"`)
+
+			So(post(m, "/debug/cron", token, "").Code, ShouldEqual, http.StatusForbidden)
+			So(post(m, "/debug/cron", "x", token).Code, ShouldEqual, http.StatusForbidden)
+
+			w = post(m, "/debug/cron?job=f1", token, token)
+			So(w.Code, ShouldEqual, http.StatusSeeOther)
+			So(w.Header().Get("Location"), ShouldEqual, "/debug/cron?job=f1")
+			So(<-started, ShouldEqual, "f1")
+		})
+
+		Convey("Test GET is not allowed", func() {
+			m := newManager()
+			w := httptest.NewRecorder()
+			m.Handler(w, httptest.NewRequest(http.MethodGet, "/debug/cron?start=f1", nil))
+			So(w.Code, ShouldEqual, http.StatusMethodNotAllowed)
+			So(w.Header().Get("Allow"), ShouldEqual, http.MethodPost)
+			So(started, ShouldBeEmpty)
+		})
+
+		Convey("Test synchronous run", func() {
+			m := newManager(WithManualRunGET())
+			w := httptest.NewRecorder()
+			m.Handler(w, httptest.NewRequest(http.MethodGet, "/debug/cron?start=f1&wait=1", nil))
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(w.Body.String(), ShouldEqual, "ok\n")
+			So(<-started, ShouldEqual, "f1")
+
+			ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+			defer cancel()
+			w = httptest.NewRecorder()
+			m.Handler(w, httptest.NewRequestWithContext(ctx, http.MethodGet, "/debug/cron?start=f2&wait=1", nil))
+			So(w.Code, ShouldEqual, http.StatusInternalServerError)
+			So(w.Body.String(), ShouldEqual, "context deadline exceeded\n")
+		})
+
+		Convey("Test GET with option", func() {
+			m := newManager(WithManualRunGET())
+			w := httptest.NewRecorder()
+			m.Handler(w, httptest.NewRequest(http.MethodGet, "/debug/cron?start=f1", nil))
+			So(w.Code, ShouldEqual, http.StatusFound)
+			So(<-started, ShouldEqual, "f1")
+		})
+	})
+}
+
+func TestManager_ToggleHandler(t *testing.T) {
+	Convey("Test enable and disable from UI", t, func() {
+		m := NewManager()
+		m.AddFunc("f1", "* * * * *", newCronFunc("f1"))
+		m.AddFunc("f2", "disabled", newCronFunc("f2"))
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+
+		post := func(url, accept string, form neturl.Values) *httptest.ResponseRecorder {
+			form.Set("csrf", strings.Repeat("a", csrfTokenLen))
+			r := httptest.NewRequest(http.MethodPost, url, strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.Header.Set("Accept", accept)
+			r.AddCookie(&http.Cookie{Name: csrfCookie, Value: strings.Repeat("a", csrfTokenLen)})
+			w := httptest.NewRecorder()
+			m.Handler(w, r)
+			return w
+		}
+		page := func() string {
+			r := httptest.NewRequest(http.MethodGet, "/debug/cron", nil)
+			r.Header.Set("Accept", "text/html")
+			w := httptest.NewRecorder()
+			m.Handler(w, r)
+			return w.Body.String()
+		}
+
+		So(page(), ShouldContainSubstring, `<input type="hidden" name="job" value="f1"><button name="action" value="disable"`)
+		So(page(), ShouldNotContainSubstring, `name="job" value="f2"`)
+
+		w := post("/debug/cron", "text/html", neturl.Values{"action": {"disable"}, "job": {"f1"}})
+		So(w.Code, ShouldEqual, http.StatusSeeOther)
+		So(w.Header().Get("Location"), ShouldEqual, "/debug/cron")
+		So(m.State()[0].LastState, ShouldEqual, "disabled")
+		So(page(), ShouldContainSubstring, `<button name="action" value="enable"`)
+
+		// manual run resets state, but job stays disabled
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		So(page(), ShouldContainSubstring, `<button name="action" value="enable"`)
+
+		w = post("/debug/cron?action=enable&job=f1", "application/json", neturl.Values{})
+		So(w.Code, ShouldEqual, http.StatusOK)
+		So(w.Body.String(), ShouldContainSubstring, `"LastState":"idle"`)
+
+		w = post("/debug/cron?action=enable&job=f3", "application/json", neturl.Values{})
+		So(w.Code, ShouldEqual, http.StatusNotFound)
+		So(w.Header().Get("Content-Type"), ShouldEqual, "application/json")
+		So(w.Body.String(), ShouldContainSubstring, `"error":"job not found"`)
+
+		So(post("/debug/cron", "", neturl.Values{"action": {"drop"}, "job": {"f1"}}).Code, ShouldEqual, http.StatusBadRequest)
+
+		w = post("/debug/cron", "", neturl.Values{"action": {"cancel"}, "job": {"f1"}})
+		So(w.Code, ShouldEqual, http.StatusConflict)
+		So(w.Body.String(), ShouldEqual, "job is not running\n")
+	})
+}
+
+func TestManager_HandlerHeaders(t *testing.T) {
+	Convey("Test handler cache and content type headers", t, func() {
+		m := NewManager()
+		m.AddFunc("f1", "* * * * *", newCronFunc("f1"))
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+
+		for accept, contentType := range map[string]string{
+			"text/html":        "text/html; charset=utf-8",
+			"application/json": "application/json",
+			"":                 "text/plain",
+		} {
+			r := httptest.NewRequest(http.MethodGet, "/debug/cron", nil)
+			r.Header.Set("Accept", accept)
+			w := httptest.NewRecorder()
+			m.Handler(w, r)
+
+			So(w.Header().Get("Content-Type"), ShouldEqual, contentType)
+			So(w.Header().Get("Cache-Control"), ShouldEqual, "no-store")
+			So(w.Header().Get("Vary"), ShouldEqual, "Accept")
+		}
+	})
+}
+
+func TestHandlerAuth(t *testing.T) {
+	Convey("Test handler authorization hook", t, func() {
+		var principal atomic.Value
+		m := NewManager(
+			WithManualRunGET(),
+			WithHistory(NewMemoryHistory(10)),
+			HandlerAuth(func(r *http.Request) (string, bool) {
+				user := r.Header.Get("X-User")
+				return user, user != ""
+			}),
+		)
+		m.AddFunc("f1", "", func(ctx context.Context) error {
+			principal.Store(PrincipalFromContext(ctx))
+			return nil
+		})
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+
+		request := func(url, user string) *httptest.ResponseRecorder {
+			r := httptest.NewRequest(http.MethodGet, url, nil)
+			if user != "" {
+				r.Header.Set("X-User", user)
+			}
+			w := httptest.NewRecorder()
+			m.Handler(w, r)
+			return w
+		}
+
+		So(request("/debug/cron", "").Code, ShouldEqual, http.StatusForbidden)
+		So(request("/debug/cron?start=f1&wait=1", "").Code, ShouldEqual, http.StatusForbidden)
+
+		w := httptest.NewRecorder()
+		m.EventsHandler(w, httptest.NewRequest(http.MethodGet, "/debug/cron/events", nil))
+		So(w.Code, ShouldEqual, http.StatusForbidden)
+
+		So(request("/debug/cron", "alice").Code, ShouldEqual, http.StatusOK)
+		So(request("/debug/cron?start=f1&wait=1", "alice").Code, ShouldEqual, http.StatusOK)
+		So(principal.Load(), ShouldEqual, "alice")
+
+		runs := m.History("f1")
+		So(runs, ShouldHaveLength, 1)
+		So(runs[0].Manual, ShouldBeTrue)
+		So(runs[0].Principal, ShouldEqual, "alice")
+	})
+}
+
+func TestHandlerBasicAuth(t *testing.T) {
+	Convey("Test handler basic auth", t, func() {
+		hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+		So(err, ShouldBeNil)
+		m := NewManager(WithManualRunGET(), HandlerBasicAuth("admin", string(hash)))
+		started := make(chan string, 1)
+		m.AddFunc("f1", "", func(ctx context.Context) error {
+			started <- PrincipalFromContext(ctx)
+			return nil
+		})
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+
+		request := func(url, user, password string) *httptest.ResponseRecorder {
+			r := httptest.NewRequest(http.MethodGet, url, nil)
+			if user != "" {
+				r.SetBasicAuth(user, password)
+			}
+			w := httptest.NewRecorder()
+			m.Handler(w, r)
+			return w
+		}
+
+		w := request("/debug/cron", "", "")
+		So(w.Code, ShouldEqual, http.StatusUnauthorized)
+		So(w.Header().Get("WWW-Authenticate"), ShouldStartWith, "Basic ")
+		So(request("/debug/cron", "admin", "wrong").Code, ShouldEqual, http.StatusUnauthorized)
+		So(request("/debug/cron", "root", "secret").Code, ShouldEqual, http.StatusUnauthorized)
+
+		So(request("/debug/cron", "admin", "secret").Code, ShouldEqual, http.StatusOK)
+		So(request("/debug/cron?start=f1&wait=1", "admin", "secret").Code, ShouldEqual, http.StatusOK)
+		So(<-started, ShouldEqual, "admin")
+
+		Convey("Test invalid hash", func() {
+			So(NewManager(HandlerBasicAuth("admin", "secret")).Run(t.Context()), ShouldNotBeNil)
+			sha := sha256.Sum256([]byte("secret"))
+			So(NewManager(HandlerBasicAuth("admin", hex.EncodeToString(sha[:]))).Run(t.Context()), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestWithHTMLTemplate(t *testing.T) {
+	Convey("Test custom html template", t, func() {
+		clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 30, 0, time.UTC)}
+		tmpl := template.Must(template.New("ui").Funcs(TemplateFuncs()).Parse(
+			`{{template "style"}}<h1>ACME</h1>{{range .States}}<p>{{.Name}}: {{formatNextRun .NextRun}}</p>{{end}}`,
+		))
+
+		m := NewManager(WithClock(clock), WithHTMLTemplate(tmpl))
+		m.AddFunc("f1", "* * * * *", newCronFunc("f1"))
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", "text/html")
+		w := httptest.NewRecorder()
+		m.Handler(w, r)
+		So(w.Code, ShouldEqual, http.StatusOK)
+		So(w.Body.String(), ShouldContainSubstring, "<style>")
+		So(w.Body.String(), ShouldContainSubstring, "<h1>ACME</h1><p>f1: ")
+	})
+}
+
+func TestWithGzip(t *testing.T) {
+	Convey("Test gzip compressed handler responses", t, func() {
+		m := NewManager(WithGzip())
+		m.AddFunc("f1", "* * * * *", newCronFunc("f1"))
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+
+		get := func(encoding string) *httptest.ResponseRecorder {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept", "application/json")
+			r.Header.Set("Accept-Encoding", encoding)
+			w := httptest.NewRecorder()
+			m.Handler(w, r)
+			return w
+		}
+
+		w := get("gzip, deflate")
+		So(w.Header().Get("Content-Encoding"), ShouldEqual, "gzip")
+		zr, err := gzip.NewReader(w.Body)
+		So(err, ShouldBeNil)
+		var states []State
+		So(json.NewDecoder(zr).Decode(&states), ShouldBeNil)
+		So(states[0].Name, ShouldEqual, "f1")
+
+		w = get("")
+		So(w.Header().Get("Content-Encoding"), ShouldBeEmpty)
+		So(json.NewDecoder(w.Body).Decode(&states), ShouldBeNil)
+	})
+}
+
+func TestWithSchedulerLog(t *testing.T) {
+	Convey("Test scheduler events are logged", t, func() {
+		lg := &testLogger{}
+		m := NewManager(WithManagerLogger(lg), WithSchedulerLog())
+		m.AddFunc("f1", "* * * * *", newCronFunc("f1"))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		time.Sleep(50 * time.Millisecond)
+		msg, args := lg.last()
+		So(msg, ShouldEqual, "cron scheduler schedule")
+		So(args[2:4], ShouldResemble, []any{"entry", cron.EntryID(1)})
+
+		<-m.Stop().Done()
+		time.Sleep(50 * time.Millisecond)
+		msg, _ = lg.last()
+		So(msg, ShouldEqual, "cron scheduler stop")
+	})
+}
+
+func TestManager_Key(t *testing.T) {
+	Convey("Test stable job keys", t, func() {
+		m := NewManager()
+		m.AddFunc("Daily Report", "0 0 * * *", newCronFunc("f1"), Key("daily-report"))
+		m.AddFunc("f2", "0 0 * * *", newCronFunc("f2"))
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+
+		st := m.State()
+		So(st[0].Key, ShouldEqual, "daily-report")
+		So(st[1].Key, ShouldEqual, "f2")
+
+		So(m.ManualRun(t.Context(), "daily-report"), ShouldBeNil)
+		So(m.ManualRun(t.Context(), "daily report"), ShouldBeNil)
+
+		Convey("Test duplicate key", func() {
+			m := NewManager()
+			m.AddFunc("f1", "0 0 * * *", newCronFunc("f1"), Key("k"))
+			m.AddFunc("f2", "0 0 * * *", newCronFunc("f2"), Key("k"))
+			So(errors.Is(m.Run(t.Context()), ErrDuplicate), ShouldBeTrue)
+		})
+	})
+}
+
+func TestManager_Reset(t *testing.T) {
+	Convey("Test reset", t, func() {
+		m := NewManager()
+		m.Use(WithMetrics("test"))
+		m.AddFunc("f1", "* * * * *", newCronFunc("f1"))
+		So(m.Run(t.Context()), ShouldBeNil)
+		So(m.State(), ShouldHaveLength, 1)
+
+		m.Reset()
+		So(m.State(), ShouldBeEmpty)
+
+		m.Use(WithMetrics("test"))
+		m.AddFunc("f1", "@every 1s", newCronFunc("f1"))
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+
+		st := m.State()
+		So(st, ShouldHaveLength, 1)
+		So(st[0].Schedule, ShouldEqual, "@every 1s")
+		So(st[0].NextRun.IsZero(), ShouldBeFalse)
+	})
+
+	Convey("Test reset waits for runs in progress", t, func() {
+		m := NewManager(WithWorkerPool(1, 1))
+		started, release := make(chan struct{}), make(chan struct{})
+		m.AddFunc("f1", "@every 1h", func(ctx context.Context) error {
+			close(started)
+			<-release
+			return nil
+		}, RunOnStart())
+		So(m.Run(t.Context()), ShouldBeNil)
+		<-started
+
+		reset := make(chan struct{})
+		go func() {
+			m.Reset()
+			close(reset)
+		}()
+
+		select {
+		case <-reset:
+			t.Error("reset returned while job is running")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(release)
+		<-reset
+		So(m.State(), ShouldBeEmpty)
+	})
+}
+
+func TestManager_OnStateChange(t *testing.T) {
+	Convey("Test state change handlers", t, func() {
+		m := NewManager()
+		m.Use(WithMinInterval(time.Hour))
+		m.AddFunc("f1", "* * * * *", newCronFunc("f1"))
+
+		var states, states2 []string
+		m.OnStateChange(func(s State) { states = append(states, s.Name+":"+s.LastState) })
+		m.OnStateChange(func(s State) { states2 = append(states2, s.LastState) })
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		So(errors.Is(m.ManualRun(t.Context(), "f1"), ErrSkipped), ShouldBeTrue)
+		So(errors.Is(m.ManualRun(t.Context(), "f1"), ErrSkipped), ShouldBeTrue)
+		So(states, ShouldResemble, []string{"f1:running", "f1:idle", "f1:running", "f1:skipped", "f1:running", "f1:skipped"})
+		So(states2, ShouldHaveLength, 6)
+	})
+}
+
+func TestManager_AddFuncErr(t *testing.T) {
+	Convey("Test fail fast on add", t, func() {
+		m := NewManager()
+		So(m.AddFuncErr("f1", "0 0 * * *", newCronFunc("f1")), ShouldBeNil)
+		So(m.AddFuncErr("f2", "", newCronFunc("f2"), Key("k")), ShouldBeNil)
+
+		err := m.AddFuncErr("F1", "0 0 * * *", newCronFunc("f1"))
+		So(errors.Is(err, ErrDuplicate), ShouldBeTrue)
+		So(err.Error(), ShouldEqual, "duplicate cron name: F1")
+
+		err = m.AddFuncErr("f3", "0 0 * * *", newCronFunc("f3"), Key("k"))
+		So(errors.Is(err, ErrDuplicate), ShouldBeTrue)
+
+		So(m.AddFuncErr("f4", "0 0 * *", newCronFunc("f4")), ShouldNotBeNil)
+		So(m.State(), ShouldHaveLength, 2)
+
+		So(m.Run(t.Context()), ShouldBeNil)
+		m.Stop()
+	})
+}
+
+func TestManager_AddAt(t *testing.T) {
+	Convey("Test one-shot run", t, func() {
+		clock := &testClock{now: time.Date(2025, 7, 1, 8, 0, 0, 0, time.UTC)}
+		m := NewManager(WithClock(clock), WithManualTicker())
+
+		var runs atomic.Int32
+		at := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+		m.AddAt("reminder", at, func(context.Context) error {
+			runs.Add(1)
+			return nil
+		})
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		sch, err := m.Schedule("reminder")
+		So(err, ShouldBeNil)
+		So(sch.Next(clock.Now()), ShouldEqual, at)
+		So(sch.Next(at), ShouldBeZeroValue)
+		So(m.State()[0].Schedule, ShouldEqual, "@at 2025-07-01T09:00:00Z")
+
+		clock.Add(30 * time.Minute)
+		So(m.Tick(t.Context(), clock.Now()), ShouldBeNil)
+		So(runs.Load(), ShouldEqual, 0)
+
+		clock.Add(time.Hour)
+		So(m.Tick(t.Context(), clock.Now()), ShouldBeNil)
+		So(m.Tick(t.Context(), clock.Now().Add(time.Hour)), ShouldBeNil)
+		So(runs.Load(), ShouldEqual, 1)
+		So(m.State()[0].LastState, ShouldEqual, "completed")
+
+		Convey("Test invalid time", func() {
+			m := NewManager()
+			m.AddFunc("f1", "@at tomorrow", newCronFunc("f1"))
+			So(m.Run(t.Context()), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestRunOnStart(t *testing.T) {
+	Convey("Test run on start", t, func() {
+		started := make(chan string, 2)
+		fn := func(ctx context.Context) error {
+			started <- NameFromContext(ctx)
+			return nil
+		}
+
+		m := NewManager()
+		m.AddFunc("f1", "@yearly", fn, RunOnStart())
+		m.AddFunc("f2", "@yearly", fn)
+		m.AddFunc("f3", "disabled", fn, RunOnStart())
+		m.AddFunc("f4", "", fn, RunOnStart())
+		m.AddFunc("f5", "@yearly", fn, RunOnStart())
+		So(m.Disable("f5"), ShouldBeNil)
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+
+		So(<-started, ShouldEqual, "f1")
+		So(m.WaitUntilIdle(t.Context()), ShouldBeNil)
+		So(started, ShouldBeEmpty)
+
+		st := m.State()[0]
+		So(st.LastState, ShouldEqual, "idle")
+		So(st.LastUpdatedAt.IsZero(), ShouldBeFalse)
+		So(st.NextRun.IsZero(), ShouldBeFalse)
+	})
+}
+
+func TestScheduleFromContext(t *testing.T) {
+	Convey("Test job schedule in context", t, func() {
+		var schedule Schedule
+		m := NewManager()
+		m.AddFunc("f1", "@every 5m # sync", func(ctx context.Context) error {
+			schedule = ScheduleFromContext(ctx)
+			return nil
+		})
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+
+		So(ScheduleFromContext(t.Context()), ShouldBeEmpty)
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		So(schedule, ShouldEqual, Schedule("@every 5m # sync"))
+
+		So(m.Reschedule("f1", "@hourly"), ShouldBeNil)
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		So(schedule, ShouldEqual, Schedule("@hourly"))
+	})
+}
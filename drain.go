@@ -0,0 +1,279 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// idlePollInterval is a check interval for WaitUntilIdle.
+const idlePollInterval = 100 * time.Millisecond
+
+// ErrGraceExpired is returned by Shutdown and RunAndServe if jobs are still running after the grace period.
+var ErrGraceExpired = errors.New("shutdown grace period expired")
+
+// Drain converts every new scheduled run into a skip with reason "draining". Scheduler keeps ticking,
+// so state and next runs are still available. Manual runs are not affected.
+// Use it with WaitUntilIdle for zero-downtime deploys.
+func (cm *Manager) Drain() {
+	cm.setDraining(true)
+}
+
+// Undrain resumes scheduled runs after Drain.
+func (cm *Manager) Undrain() {
+	cm.setDraining(false)
+}
+
+// Draining returns true if manager is drained.
+func (cm *Manager) Draining() bool {
+	return cm.draining.Load()
+}
+
+func (cm *Manager) setDraining(v bool) {
+	cm.draining.Store(v)
+
+	var g float64
+	if v {
+		g = 1
+	}
+	metricDraining().WithLabelValues(cm.name).Set(g)
+}
+
+// WaitUntilIdle waits until there are no running jobs (see Running) or ctx is done.
+func (cm *Manager) WaitUntilIdle(ctx context.Context) error {
+	t := time.NewTicker(idlePollInterval)
+	defer t.Stop()
+
+	for len(cm.Running()) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+
+	return nil
+}
+
+// newDrainFunc wraps scheduled job fn with drain check.
+func (cm *Manager) newDrainFunc(idx int, fn Func) Func {
+	return func(ctx context.Context) error {
+		if cm.draining.Load() {
+			err := newSkipError(SkipDraining, "draining")
+			cm.skipRun(idx, err)
+			return err
+		}
+
+		return fn(ctx)
+	}
+}
+
+// ShutdownStatus is a job run outcome on Shutdown.
+type ShutdownStatus string
+
+const (
+	ShutdownCompleted ShutdownStatus = "completed" // run finished within grace period
+	ShutdownCancelled ShutdownStatus = "cancelled" // run context was cancelled after grace period, run returned
+	ShutdownExceeded  ShutdownStatus = "exceeded"  // run was still running after cancellation
+)
+
+// shutdownCancelWait is time to wait for a run to return after its context was cancelled on Shutdown.
+var shutdownCancelWait = time.Second
+
+// ShutdownResult is an outcome of job run which was running on Shutdown.
+type ShutdownResult struct {
+	Job    string
+	Status ShutdownStatus
+}
+
+// activeRun is a running job invocation, see Shutdown.
+type activeRun struct {
+	idx    int
+	cancel context.CancelCauseFunc
+	done   chan struct{}
+}
+
+// trackRun registers running job invocation with cancellable context.
+func (cm *Manager) trackRun(ctx context.Context, idx int) (context.Context, *activeRun) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	r := &activeRun{idx: idx, cancel: cancel, done: make(chan struct{})}
+
+	cm.muRuns.Lock()
+	defer cm.muRuns.Unlock()
+	if cm.runs == nil {
+		cm.runs = make(map[*activeRun]struct{})
+	}
+	cm.runs[r] = struct{}{}
+
+	return ctx, r
+}
+
+// untrackRun removes finished job invocation.
+func (cm *Manager) untrackRun(r *activeRun) {
+	cm.muRuns.Lock()
+	delete(cm.runs, r)
+	cm.muRuns.Unlock()
+
+	close(r.done)
+	r.cancel(nil)
+}
+
+// Cancel cancels running invocations of the job with ErrCancelled cause, their error is shown as "cancelled by operator"
+// with principal from ctx if set (see HandlerAuth). It returns ErrNotRunning if the job is not running.
+func (cm *Manager) Cancel(ctx context.Context, name string) error {
+	idx := cm.jobIndex(name)
+	if idx < 0 {
+		return ErrNotFound
+	}
+
+	cause := ErrCancelled
+	if principal := PrincipalFromContext(ctx); principal != "" {
+		cause = fmt.Errorf("%w: %s", ErrCancelled, principal)
+	}
+
+	// runs are cancelled under lock: finished runs are already removed by untrackRun
+	cm.muRuns.Lock()
+	defer cm.muRuns.Unlock()
+
+	var n int
+	for r := range cm.runs {
+		if r.idx == idx {
+			r.cancel(cause)
+			n++
+		}
+	}
+	if n == 0 {
+		return ErrNotRunning
+	}
+
+	return nil
+}
+
+// isRunning checks if the job has running invocations.
+func (cm *Manager) isRunning(idx int) bool {
+	cm.muRuns.Lock()
+	defer cm.muRuns.Unlock()
+
+	for r := range cm.runs {
+		if r.idx == idx {
+			return true
+		}
+	}
+
+	return false
+}
+
+// activeRuns returns running job invocations.
+func (cm *Manager) activeRuns() []*activeRun {
+	cm.muRuns.Lock()
+	defer cm.muRuns.Unlock()
+
+	return slices.Collect(maps.Keys(cm.runs))
+}
+
+// Shutdown drains the manager, stops the scheduler and waits for running jobs (including manual runs).
+// Jobs with ShutdownGrace keep running within their grace period, other jobs get their contexts cancelled immediately.
+// Cancelled jobs are waited for until ctx is done. Grace period is not limited by ctx, so a job with ShutdownGrace
+// can keep Shutdown blocked after ctx is done.
+// Shutdown returns outcome for every run and ErrGraceExpired with names of runs not completed in time.
+func (cm *Manager) Shutdown(ctx context.Context) ([]ShutdownResult, error) {
+	return cm.shutdown(ctx, 0)
+}
+
+// shutdown is Shutdown with default grace period for jobs without ShutdownGrace.
+func (cm *Manager) shutdown(ctx context.Context, grace time.Duration) ([]ShutdownResult, error) {
+	cm.Drain()
+	cm.Stop()
+
+	runs := cm.activeRuns()
+	res := make([]ShutdownResult, len(runs))
+	var wg sync.WaitGroup
+	for i, r := range runs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res[i] = ShutdownResult{Job: cm.jobs[r.idx].name, Status: cm.awaitRun(ctx, r, grace)}
+		}()
+	}
+	wg.Wait()
+
+	slices.SortFunc(res, func(a, b ShutdownResult) int { return strings.Compare(a.Job, b.Job) })
+
+	var abandoned []string
+	for _, r := range res {
+		if r.Status != ShutdownCompleted {
+			abandoned = append(abandoned, r.Job)
+		}
+	}
+	if len(abandoned) > 0 {
+		return res, fmt.Errorf("%w, abandoned jobs: %s", ErrGraceExpired, strings.Join(abandoned, ", "))
+	}
+
+	return res, nil
+}
+
+// awaitRun waits for run within its grace period (ShutdownGrace or grace) and cancels it after.
+func (cm *Manager) awaitRun(ctx context.Context, r *activeRun, grace time.Duration) ShutdownStatus {
+	if g := cm.jobs[r.idx].shutdownGrace; g > 0 {
+		grace = g
+	}
+
+	if grace > 0 {
+		gt := time.NewTimer(grace)
+		defer gt.Stop()
+
+		select {
+		case <-r.done:
+			return ShutdownCompleted
+		case <-gt.C:
+		}
+	}
+
+	r.cancel(ErrGraceExpired)
+	t := time.NewTimer(shutdownCancelWait)
+	defer t.Stop()
+
+	// cancelled run is waited for at least shutdownCancelWait and until ctx is done
+	select {
+	case <-r.done:
+		return ShutdownCancelled
+	case <-t.C:
+	}
+
+	select {
+	case <-r.done:
+		return ShutdownCancelled
+	case <-ctx.Done():
+		return ShutdownExceeded
+	}
+}
+
+// RunAndServe runs the manager and blocks until SIGTERM, SIGINT or ctx cancellation, then shuts it down
+// like Shutdown: grace is a grace period for jobs without ShutdownGrace.
+func (cm *Manager) RunAndServe(ctx context.Context, grace time.Duration) error {
+	sigCtx, stop := signal.NotifyContext(ctx, syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	// running jobs should not observe ctx cancellation until grace period expires
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+
+	if err := cm.Run(runCtx); err != nil {
+		return err
+	}
+	<-sigCtx.Done()
+
+	graceCtx, graceCancel := context.WithTimeout(context.WithoutCancel(ctx), grace)
+	defer graceCancel()
+
+	_, err := cm.shutdown(graceCtx, grace)
+	return err
+}
@@ -0,0 +1,214 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestManager_Drain(t *testing.T) {
+	Convey("Test drain mode", t, func() {
+		clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 30, 0, time.UTC)}
+		m := NewManager(WithClock(clock), WithManualTicker())
+
+		var runs int
+		release := make(chan struct{})
+		m.AddFunc("f1", "* * * * *", func(context.Context) error {
+			runs++
+			return nil
+		})
+		m.AddFunc("long", "@yearly", func(context.Context) error {
+			<-release
+			return nil
+		})
+		So(m.Run(t.Context()), ShouldBeNil)
+		wait := watchStates(m)
+
+		m.Drain()
+		So(m.Draining(), ShouldBeTrue)
+
+		clock.Add(time.Minute)
+		So(errors.Is(m.Tick(t.Context(), clock.Now()), ErrSkipped), ShouldBeTrue)
+		So(runs, ShouldEqual, 0)
+
+		st := m.State()[0]
+		So(st.Draining, ShouldBeTrue)
+		So(st.LastState, ShouldEqual, "skipped")
+		So(st.SkipReason, ShouldEqual, "draining")
+
+		Convey("Test wait until idle", func() {
+			go func() { _ = m.ManualRun(context.Background(), "long") }()
+			wait("long", "running")
+			So(m.Running(), ShouldResemble, []string{"long"})
+
+			ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+			defer cancel()
+			So(m.WaitUntilIdle(ctx), ShouldEqual, context.DeadlineExceeded)
+
+			close(release)
+			So(m.WaitUntilIdle(t.Context()), ShouldBeNil)
+		})
+
+		Convey("Test scheduled skip keeps running state", func() {
+			go func() { _ = m.ManualRun(context.Background(), "long") }()
+			wait("long", "running")
+
+			So(errors.Is(m.jobs[1].schedFn(t.Context()), ErrSkipped), ShouldBeTrue)
+			st := m.State()[1]
+			So(st.LastState, ShouldEqual, "running")
+			So(st.SkipReason, ShouldEqual, "draining")
+			So(m.Running(), ShouldResemble, []string{"long"})
+
+			ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+			defer cancel()
+			So(m.WaitUntilIdle(ctx), ShouldEqual, context.DeadlineExceeded)
+
+			close(release)
+			So(m.WaitUntilIdle(t.Context()), ShouldBeNil)
+			So(m.State()[1].LastState, ShouldEqual, "idle")
+		})
+
+		Convey("Test undrain", func() {
+			m.Undrain()
+			clock.Add(time.Minute)
+			So(m.Tick(t.Context(), clock.Now()), ShouldBeNil)
+			So(runs, ShouldEqual, 1)
+			So(m.State()[0].Draining, ShouldBeFalse)
+		})
+	})
+}
+
+func TestManager_RunAndServe(t *testing.T) {
+	Convey("Test graceful shutdown", t, func() {
+		m := NewManager()
+
+		var cancelled atomic.Bool
+		release := make(chan struct{})
+		m.AddFunc("short", "", newCronFunc("short"))
+		m.AddFunc("long", "", func(ctx context.Context) error {
+			select {
+			case <-release:
+			case <-ctx.Done():
+				cancelled.Store(true)
+			}
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(t.Context())
+		errc := make(chan error)
+		go func() { errc <- m.RunAndServe(ctx, 100*time.Millisecond) }()
+		time.Sleep(50 * time.Millisecond)
+
+		wait := watchStates(m)
+		go func() { _ = m.ManualRun(context.Background(), "long") }()
+		wait("long", "running")
+
+		cancel()
+		err := <-errc
+		So(errors.Is(err, ErrGraceExpired), ShouldBeTrue)
+		So(err.Error(), ShouldEqual, "shutdown grace period expired, abandoned jobs: long")
+		So(m.Draining(), ShouldBeTrue)
+		So(cancelled.Load(), ShouldBeTrue)
+
+		close(release)
+		res, err := m.Shutdown(t.Context())
+		So(err, ShouldBeNil)
+		So(res, ShouldBeEmpty)
+	})
+}
+
+func TestManager_ShutdownGrace(t *testing.T) {
+	Convey("Test per-job shutdown grace", t, func() {
+		defer func(d time.Duration) { shutdownCancelWait = d }(shutdownCancelWait)
+		shutdownCancelWait = 50 * time.Millisecond
+
+		release, stubborn := make(chan struct{}), make(chan struct{})
+		defer close(stubborn)
+
+		var cause atomic.Value
+		m := NewManager()
+		m.AddFunc("export", "", func(ctx context.Context) error {
+			select {
+			case <-release:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}, ShutdownGrace(time.Second))
+		m.AddFunc("cleanup", "", func(ctx context.Context) error {
+			<-ctx.Done()
+			cause.Store(context.Cause(ctx))
+			return nil
+		})
+		m.AddFunc("stubborn", "", func(context.Context) error {
+			<-stubborn
+			return nil
+		})
+		m.AddFunc("quick", "", newCronFunc("quick"))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		wait := watchStates(m)
+		for _, name := range []string{"export", "cleanup", "stubborn"} {
+			go func() { _ = m.ManualRun(context.Background(), name) }()
+			wait(name, "running")
+		}
+		time.AfterFunc(200*time.Millisecond, func() { close(release) })
+
+		ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		defer cancel()
+
+		res, err := m.Shutdown(ctx)
+		So(errors.Is(err, ErrGraceExpired), ShouldBeTrue)
+		So(err.Error(), ShouldEqual, "shutdown grace period expired, abandoned jobs: cleanup, stubborn")
+		So(res, ShouldResemble, []ShutdownResult{
+			{Job: "cleanup", Status: ShutdownCancelled},
+			{Job: "export", Status: ShutdownCompleted},
+			{Job: "stubborn", Status: ShutdownExceeded},
+		})
+		So(cause.Load(), ShouldEqual, ErrGraceExpired)
+	})
+}
+
+func TestManager_Cancel(t *testing.T) {
+	Convey("Test cancel running job", t, func() {
+		m := NewManager()
+		started := make(chan struct{})
+		m.AddFunc("f1", "@yearly", func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+
+		So(m.Cancel(t.Context(), "f2"), ShouldEqual, ErrNotFound)
+		So(m.Cancel(t.Context(), "f1"), ShouldEqual, ErrNotRunning)
+
+		done := make(chan error)
+		go func() { done <- m.ManualRun(context.Background(), "f1") }()
+		<-started
+
+		// overrun job can be cancelled from UI
+		m.markOverrun(0)
+		So(m.State()[0].Running, ShouldBeTrue)
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", "text/html")
+		w := httptest.NewRecorder()
+		m.Handler(w, r)
+		So(w.Body.String(), ShouldContainSubstring, `value="cancel"`)
+
+		So(m.Cancel(NewPrincipalContext(t.Context(), "alice"), "f1"), ShouldBeNil)
+		err := <-done
+		So(errors.Is(err, ErrCancelled), ShouldBeTrue)
+
+		st := m.State()[0]
+		So(st.LastErr.Error(), ShouldEqual, "cancelled by operator: alice")
+		So(m.Cancel(t.Context(), "f1"), ShouldEqual, ErrNotRunning)
+	})
+}
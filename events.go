@@ -0,0 +1,115 @@
+package cron
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// streamChanged is a stream query value and event data for change notifications without snapshots.
+const streamChanged = "changed"
+
+// eventsKeepAlive is an interval of keep-alive comments in events stream, so proxies don't close idle connections.
+var eventsKeepAlive = 15 * time.Second
+
+// eventBus notifies EventsHandler subscribers about state changes.
+type eventBus struct {
+	once sync.Once
+	mu   sync.Mutex
+	subs map[chan struct{}]struct{}
+}
+
+// subscribe returns channel which receives a signal after state changes. Bursts are coalesced.
+func (b *eventBus) subscribe() chan struct{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.subs == nil {
+		b.subs = make(map[chan struct{}]struct{})
+	}
+
+	ch := make(chan struct{}, 1)
+	b.subs[ch] = struct{}{}
+	return ch
+}
+
+func (b *eventBus) unsubscribe(ch chan struct{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.subs, ch)
+}
+
+func (b *eventBus) publish(State) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// EventsHandler streams States snapshots as Server-Sent Events on every job state change with keep-alive pings.
+// With stream=changed query only "changed" events are sent without snapshots, UI uses them to reload the page.
+// Handler serves it for requests with "Accept: text/event-stream" header or stream query.
+func (cm *Manager) EventsHandler(w http.ResponseWriter, r *http.Request) {
+	if r, ok := cm.authorize(w, r); ok {
+		cm.streamEvents(w, r)
+	}
+}
+
+// streamEvents streams States snapshots, see EventsHandler.
+func (cm *Manager) streamEvents(w http.ResponseWriter, r *http.Request) {
+	fl, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	cm.events.once.Do(func() { cm.OnStateChange(cm.events.publish) })
+	ch := cm.events.subscribe()
+	defer cm.events.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ping := time.NewTicker(eventsKeepAlive)
+	defer ping.Stop()
+
+	changedOnly := r.URL.Query().Get("stream") == streamChanged
+	for {
+		b := []byte(streamChanged)
+		if !changedOnly {
+			var err error
+			if b, err = json.Marshal(cm.State()); err != nil {
+				return
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", b); err != nil {
+			return
+		}
+		fl.Flush()
+
+		// wait for state change, ping idle connection
+		for changed := false; !changed; {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ping.C:
+				if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return
+				}
+				fl.Flush()
+			case <-ch:
+				changed = true
+			}
+		}
+	}
+}
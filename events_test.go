@@ -0,0 +1,120 @@
+package cron
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestManager_EventsHandler(t *testing.T) {
+	Convey("Test state changes are streamed as server-sent events", t, func() {
+		m := NewManager()
+		m.AddFunc("f1", "", func(context.Context) error { return nil })
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		srv := httptest.NewServer(http.HandlerFunc(m.Handler))
+		defer srv.Close()
+
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		So(err, ShouldBeNil)
+		req.Header.Set("Accept", "text/event-stream")
+		resp, err := http.DefaultClient.Do(req)
+		So(err, ShouldBeNil)
+		defer resp.Body.Close()
+		So(resp.Header.Get("Content-Type"), ShouldEqual, "text/event-stream")
+
+		sc := bufio.NewScanner(resp.Body)
+		next := func() []State {
+			for sc.Scan() {
+				if data, ok := strings.CutPrefix(sc.Text(), "data: "); ok {
+					var states []State
+					So(json.Unmarshal([]byte(data), &states), ShouldBeNil)
+					return states
+				}
+			}
+			return nil
+		}
+
+		So(next()[0].LastState, ShouldEqual, "unscheduled")
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+
+		// snapshots are coalesced, so wait for the final one
+		st := next()
+		for st != nil && st[0].LastState != "idle" {
+			st = next()
+		}
+		So(st, ShouldNotBeNil)
+	})
+}
+
+func TestManager_EventsStreamQuery(t *testing.T) {
+	Convey("Test events stream by query with keep-alive pings", t, func() {
+		defer func(d time.Duration) { eventsKeepAlive = d }(eventsKeepAlive)
+		eventsKeepAlive = 10 * time.Millisecond
+
+		m := NewManager()
+		m.AddFunc("f1", "", func(context.Context) error { return nil })
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		srv := httptest.NewServer(http.HandlerFunc(m.Handler))
+		defer srv.Close()
+
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"?stream=1", nil)
+		So(err, ShouldBeNil)
+		resp, err := http.DefaultClient.Do(req)
+		So(err, ShouldBeNil)
+		defer resp.Body.Close()
+		So(resp.Header.Get("Content-Type"), ShouldEqual, "text/event-stream")
+
+		var lines []string
+		sc := bufio.NewScanner(resp.Body)
+		for sc.Scan() && !slices.Contains(lines, ": ping") {
+			lines = append(lines, sc.Text())
+		}
+		So(lines[0], ShouldStartWith, "data: ")
+		So(lines, ShouldContain, ": ping")
+	})
+
+	Convey("Test change events without snapshots", t, func() {
+		m := NewManager()
+		m.AddFunc("f1", "", func(context.Context) error { return nil })
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		srv := httptest.NewServer(http.HandlerFunc(m.Handler))
+		defer srv.Close()
+
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"?stream=changed", nil)
+		So(err, ShouldBeNil)
+		resp, err := http.DefaultClient.Do(req)
+		So(err, ShouldBeNil)
+		defer resp.Body.Close()
+
+		sc := bufio.NewScanner(resp.Body)
+		So(sc.Scan(), ShouldBeTrue)
+		So(sc.Text(), ShouldEqual, "data: changed")
+
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		var line string
+		for line == "" && sc.Scan() {
+			line = sc.Text()
+		}
+		So(line, ShouldEqual, "data: changed")
+	})
+}
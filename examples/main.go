@@ -41,7 +41,7 @@ func main() {
 
 	sl := NewLogger(false)
 	ctx := context.Background()
-	m := cron.NewManager()
+	m := cron.NewManager(cron.WithName("test-run"))
 	m.Use(
 		cron.WithMetrics("test"),
 		cron.WithDevel(false),
@@ -0,0 +1,159 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// maxExtendedDays limits days search for extended schedules.
+const maxExtendedDays = 366 * 5
+
+var ErrInvalidToken = errors.New("invalid schedule token")
+
+// WithExtendedSyntax enables Quartz-style tokens in schedules: L (last day of month), LW (last weekday of month),
+// 15W (nearest weekday to 15th) in day of month field and 5#3 (third Friday), 5L (last Friday) in day of week field.
+// Other field is applied too, e.g. "0 9 L * 1-5" runs on the last day of month only if it is a weekday.
+func WithExtendedSyntax() Option {
+	return func(cm *Manager) {
+		cm.extendedSyntax = true
+	}
+}
+
+// extendedSchedule filters activations of base schedule by day.
+type extendedSchedule struct {
+	base  cron.Schedule
+	match func(t time.Time) bool
+}
+
+// Next implements cron.Schedule.
+func (s extendedSchedule) Next(t time.Time) time.Time {
+	for range maxExtendedDays {
+		t = s.base.Next(t)
+		if t.IsZero() || s.match(t) {
+			return t
+		}
+
+		// jump to the end of the day
+		y, m, d := t.Date()
+		t = time.Date(y, m, d+1, 0, 0, 0, 0, t.Location()).Add(-time.Second)
+	}
+
+	return time.Time{}
+}
+
+// parseExtended parses standard spec with L, W and # tokens.
+func parseExtended(spec string) (cron.Schedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 || !strings.ContainsAny(fields[2]+fields[4], "LW#") {
+		return cron.ParseStandard(spec)
+	}
+
+	var matchers []func(time.Time) bool
+	if dom := fields[2]; strings.ContainsAny(dom, "LW#") {
+		fn, err := parseDayOfMonth(dom)
+		if err != nil {
+			return nil, err
+		}
+		matchers, fields[2] = append(matchers, fn), "*"
+	}
+
+	if dow := fields[4]; strings.ContainsAny(dow, "LW#") {
+		fn, err := parseDayOfWeek(dow)
+		if err != nil {
+			return nil, err
+		}
+		matchers, fields[4] = append(matchers, fn), "*"
+	}
+
+	base, err := cron.ParseStandard(strings.Join(fields, " "))
+	if err != nil {
+		return nil, err
+	}
+
+	return extendedSchedule{base: base, match: func(t time.Time) bool {
+		for _, fn := range matchers {
+			if !fn(t) {
+				return false
+			}
+		}
+		return true
+	}}, nil
+}
+
+// parseDayOfMonth parses L, LW and nW tokens.
+func parseDayOfMonth(token string) (func(time.Time) bool, error) {
+	switch {
+	case token == "L":
+		return func(t time.Time) bool { return t.Day() == lastDay(t) }, nil
+	case token == "LW":
+		return func(t time.Time) bool { return t.Day() == nearestWeekday(t, lastDay(t)) }, nil
+	case strings.HasSuffix(token, "W"):
+		n, err := strconv.Atoi(strings.TrimSuffix(token, "W"))
+		if err != nil || n < 1 || n > 31 {
+			return nil, fmt.Errorf("%w %q in day of month", ErrInvalidToken, token)
+		}
+		return func(t time.Time) bool { return n <= lastDay(t) && t.Day() == nearestWeekday(t, n) }, nil
+	}
+
+	return nil, fmt.Errorf("%w %q in day of month", ErrInvalidToken, token)
+}
+
+// parseDayOfWeek parses d#n and dL tokens.
+func parseDayOfWeek(token string) (func(time.Time) bool, error) {
+	if d, ok := strings.CutSuffix(token, "L"); ok {
+		wd, err := parseWeekday(d)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q in day of week", ErrInvalidToken, token)
+		}
+		return func(t time.Time) bool { return t.Weekday() == wd && t.Day()+7 > lastDay(t) }, nil
+	}
+
+	d, nth, ok := strings.Cut(token, "#")
+	wd, err := parseWeekday(d)
+	n, nErr := strconv.Atoi(nth)
+	if !ok || err != nil || nErr != nil || n < 1 || n > 5 {
+		return nil, fmt.Errorf("%w %q in day of week", ErrInvalidToken, token)
+	}
+
+	return func(t time.Time) bool { return t.Weekday() == wd && (t.Day()-1)/7+1 == n }, nil
+}
+
+// parseWeekday parses 0-7 weekday, 7 is Sunday.
+func parseWeekday(s string) (time.Weekday, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > 7 {
+		return 0, errors.New("invalid weekday")
+	}
+
+	return time.Weekday(n % 7), nil
+}
+
+// lastDay returns last day of t month.
+func lastDay(t time.Time) int {
+	y, m, _ := t.Date()
+	return time.Date(y, m+1, 0, 0, 0, 0, 0, t.Location()).Day()
+}
+
+// nearestWeekday returns weekday nearest to day n of t month without leaving the month.
+func nearestWeekday(t time.Time, n int) int {
+	y, m, _ := t.Date()
+	switch time.Date(y, m, n, 0, 0, 0, 0, t.Location()).Weekday() {
+	case time.Saturday:
+		if n == 1 {
+			return n + 2
+		}
+		return n - 1
+	case time.Sunday:
+		if n == lastDay(t) {
+			return n - 2
+		}
+		return n + 1
+	default:
+		return n
+	}
+}
@@ -0,0 +1,73 @@
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseExtended(t *testing.T) {
+	Convey("Test extended syntax", t, func() {
+		next := func(spec string, from time.Time, n int) []string {
+			s, err := parseExtended(spec)
+			So(err, ShouldBeNil)
+
+			var rr []string
+			for range n {
+				from = s.Next(from)
+				rr = append(rr, from.Format("2006-01-02 15:04 Mon"))
+			}
+			return rr
+		}
+		from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		Convey("Test last day of month", func() {
+			So(next("0 18 L * *", from, 3), ShouldResemble, []string{"2025-01-31 18:00 Fri", "2025-02-28 18:00 Fri", "2025-03-31 18:00 Mon"})
+			So(next("0 18 LW * *", time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), 1), ShouldResemble, []string{"2025-05-30 18:00 Fri"})
+		})
+
+		Convey("Test nearest weekday", func() {
+			// 2025-02-15 and 2025-02-01 are Saturdays
+			So(next("0 9 15W * *", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), 1), ShouldResemble, []string{"2025-02-14 09:00 Fri"})
+			So(next("0 9 1W * *", time.Date(2025, 2, 2, 0, 0, 0, 0, time.UTC), 1), ShouldResemble, []string{"2025-02-03 09:00 Mon"})
+		})
+
+		Convey("Test nth and last weekday", func() {
+			So(next("0 9 * * 2#2", from, 2), ShouldResemble, []string{"2025-01-14 09:00 Tue", "2025-02-11 09:00 Tue"})
+			So(next("0 9 * * 5L", from, 2), ShouldResemble, []string{"2025-01-31 09:00 Fri", "2025-02-28 09:00 Fri"})
+			So(next("0 9 L * 1-5", from, 2), ShouldResemble, []string{"2025-01-31 09:00 Fri", "2025-02-28 09:00 Fri"})
+		})
+
+		Convey("Test standard spec", func() {
+			So(next("0 9 1 * *", from, 1), ShouldResemble, []string{"2025-01-01 09:00 Wed"})
+		})
+
+		Convey("Test invalid tokens", func() {
+			for _, spec := range []string{"0 9 32W * *", "0 9 XL * *", "0 9 * * 2#6", "0 9 * * 8L", "0 9 * * 2#"} {
+				_, err := parseExtended(spec)
+				So(errors.Is(err, ErrInvalidToken), ShouldBeTrue)
+			}
+
+			_, err := parseExtended("0 9 * * 2#6")
+			So(err.Error(), ShouldContainSubstring, `"2#6"`)
+		})
+
+		Convey("Test manager option", func() {
+			m := NewManager()
+			m.AddFunc("f1", "0 9 * * 5#3", newCronFunc("f1"))
+			_, err := m.validateJobs()
+			So(err, ShouldNotBeNil)
+
+			m = NewManager(WithExtendedSyntax())
+			m.AddFunc("f1", "0 9 * * 5#3", newCronFunc("f1"))
+			So(m.Run(t.Context()), ShouldBeNil)
+			defer m.Stop()
+
+			st := m.State()[0]
+			So(st.NextRun.Weekday(), ShouldEqual, time.Friday)
+			So((st.NextRun.Day()-1)/7, ShouldEqual, 2)
+		})
+	})
+}
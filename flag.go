@@ -0,0 +1,61 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+)
+
+// FlagProvider checks feature flags.
+type FlagProvider interface {
+	Enabled(ctx context.Context, flag string) (bool, error)
+}
+
+// FlagProviderFunc is an adapter to use ordinary functions as FlagProvider.
+type FlagProviderFunc func(ctx context.Context, flag string) (bool, error)
+
+// Enabled implements FlagProvider.
+func (f FlagProviderFunc) Enabled(ctx context.Context, flag string) (bool, error) {
+	return f(ctx, flag)
+}
+
+// WithFlag runs jobs only if feature flag is enabled, otherwise run is skipped.
+// Provider is checked on every run. Provider errors and panics are treated as disabled flag.
+func WithFlag(flag string, provider FlagProvider) MiddlewareFunc {
+	return withFlag(flag, provider, false)
+}
+
+// WithFlagFailOpen is like WithFlag, but provider errors and panics are treated as enabled flag.
+func WithFlagFailOpen(flag string, provider FlagProvider) MiddlewareFunc {
+	return withFlag(flag, provider, true)
+}
+
+func withFlag(flag string, provider FlagProvider, failOpen bool) MiddlewareFunc {
+	return func(next Func) Func {
+		return func(ctx context.Context) error {
+			enabled, err := flagEnabled(ctx, flag, provider)
+			if err != nil {
+				enabled = failOpen
+			}
+
+			if !enabled {
+				if err != nil {
+					return wrapSkipError(err, SkipGuard, "flag %s check failed: %v", flag, err)
+				}
+				return newSkipError(SkipDisabled, "flag %s is disabled", flag)
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// flagEnabled calls provider and converts its panic to error.
+func flagEnabled(ctx context.Context, flag string, provider FlagProvider) (enabled bool, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("flag provider panic: %v", rec)
+		}
+	}()
+
+	return provider.Enabled(ctx, flag)
+}
@@ -0,0 +1,38 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithFlag(t *testing.T) {
+	Convey("Test feature flag middleware", t, func() {
+		flags := map[string]bool{"on": true, "off": false}
+		provider := FlagProviderFunc(func(_ context.Context, flag string) (bool, error) {
+			switch flag {
+			case "error":
+				return false, errors.New("provider unavailable")
+			case "panic":
+				panic("provider panic")
+			}
+			return flags[flag], nil
+		})
+
+		fn := newCronFunc("f1")
+		So(WithFlag("on", provider)(fn)(t.Context()), ShouldBeNil)
+		So(errors.Is(WithFlag("off", provider)(fn)(t.Context()), ErrSkipped), ShouldBeTrue)
+		So(errors.Is(WithFlag("error", provider)(fn)(t.Context()), ErrSkipped), ShouldBeTrue)
+		So(errors.Is(WithFlag("panic", provider)(fn)(t.Context()), ErrSkipped), ShouldBeTrue)
+		So(WithFlagFailOpen("error", provider)(fn)(t.Context()), ShouldBeNil)
+		So(WithFlagFailOpen("panic", provider)(fn)(t.Context()), ShouldBeNil)
+
+		m := NewManager()
+		m.AddFunc("f1", "", fn, JobMiddleware(WithFlag("off", provider)))
+		So(m.Run(t.Context()), ShouldBeNil)
+		_ = m.ManualRun(t.Context(), "f1")
+		So(m.State()[0].SkipReason, ShouldEqual, "flag off is disabled")
+	})
+}
@@ -0,0 +1,78 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// WithGuard calls check before each run and skips the run if check returns error, e.g. to shed load
+// when the host is under pressure. Error text is used as skip reason. Check panics are recovered and
+// also skip the run.
+func WithGuard(check func(ctx context.Context) error) MiddlewareFunc {
+	return func(next Func) Func {
+		return func(ctx context.Context) error {
+			if err := safeCheck(ctx, check); err != nil {
+				return wrapSkipError(err, SkipGuard, "%s", err)
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// safeCheck calls check and converts its panic to error.
+func safeCheck(ctx context.Context, check func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("guard panic: %v", r)
+		}
+	}()
+
+	return check(ctx)
+}
+
+// RuntimeGuard returns WithGuard check which fails if heap in use exceeds maxHeap bytes
+// or number of goroutines exceeds maxGoroutines. Zero limit is not checked.
+func RuntimeGuard(maxHeap uint64, maxGoroutines int) func(ctx context.Context) error {
+	return func(context.Context) error {
+		if n := runtime.NumGoroutine(); maxGoroutines > 0 && n > maxGoroutines {
+			return fmt.Errorf("too many goroutines: %d > %d", n, maxGoroutines)
+		}
+
+		if maxHeap > 0 {
+			var ms runtime.MemStats
+			runtime.ReadMemStats(&ms)
+			if ms.HeapInuse > maxHeap {
+				return fmt.Errorf("heap in use is too high: %d > %d bytes", ms.HeapInuse, maxHeap)
+			}
+		}
+
+		return nil
+	}
+}
+
+// dependencyPingTimeout is a timeout of WithDependency ping.
+const dependencyPingTimeout = 5 * time.Second
+
+// WithDependency pings dependency (e.g. database) before each run and skips the run if ping fails,
+// so that outage doesn't turn into failures of every job. Skips are counted in app_cron_dependency_skipped_total
+// metric (with app label if WithMetrics is added before). Add several WithDependency to check dependencies in order.
+func WithDependency(name string, ping func(ctx context.Context) error) MiddlewareFunc {
+	return func(next Func) Func {
+		return func(ctx context.Context) error {
+			pingCtx, cancel := context.WithTimeout(ctx, dependencyPingTimeout)
+			err := safeCheck(pingCtx, ping)
+			cancel()
+
+			if err != nil {
+				app, _ := metricsAppFromContext(ctx)
+				metricDependencySkipped().WithLabelValues(app, NameFromContext(ctx), name).Inc()
+				return wrapSkipError(err, SkipGuard, "dependency %s is down: %s", name, err)
+			}
+
+			return next(ctx)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithGuard(t *testing.T) {
+	Convey("Test guard skips runs under pressure", t, func() {
+		var pressure error
+		m := NewManager()
+		m.AddFunc("f1", "", newCronFunc("f1"), JobMiddleware(WithGuard(func(context.Context) error { return pressure })))
+		m.AddFunc("f2", "", newCronFunc("f2"), JobMiddleware(WithGuard(func(context.Context) error { panic("boom") })))
+		m.AddFunc("f3", "", newCronFunc("f3"), JobMiddleware(WithGuard(RuntimeGuard(0, 1))))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+
+		pressure = errors.New("load average is 12.5")
+		err := m.ManualRun(t.Context(), "f1")
+		So(errors.Is(err, ErrSkipped), ShouldBeTrue)
+		So(errors.Is(err, pressure), ShouldBeTrue)
+		So(m.State()[0].SkipReason, ShouldEqual, "load average is 12.5")
+
+		So(errors.Is(m.ManualRun(t.Context(), "f2"), ErrSkipped), ShouldBeTrue)
+		So(m.State()[1].SkipReason, ShouldEqual, "guard panic: boom")
+
+		So(errors.Is(m.ManualRun(t.Context(), "f3"), ErrSkipped), ShouldBeTrue)
+		So(m.State()[2].SkipReason, ShouldStartWith, "too many goroutines: ")
+	})
+}
+
+func TestWithDependency(t *testing.T) {
+	Convey("Test dependencies are checked in order", t, func() {
+		var dbErr, redisErr error
+		m := NewManager()
+		m.Use(WithMetrics("test-dependency"))
+		skipped := metricDelta("app_cron_dependency_skipped_total", map[string]string{"app": "test-dependency", "cron": "f1", "dependency": "redis"})
+		m.AddFunc("f1", "", newCronFunc("f1"), JobMiddleware(
+			WithDependency("db", func(context.Context) error { return dbErr }),
+			WithDependency("redis", func(context.Context) error { return redisErr }),
+		))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+
+		redisErr = errors.New("connection refused")
+		So(errors.Is(m.ManualRun(t.Context(), "f1"), ErrSkipped), ShouldBeTrue)
+		So(m.State()[0].SkipReason, ShouldEqual, "dependency redis is down: connection refused")
+		So(skipped(), ShouldEqual, 1)
+
+		dbErr = errors.New("too many connections")
+		So(errors.Is(m.ManualRun(t.Context(), "f1"), ErrSkipped), ShouldBeTrue)
+		So(m.State()[0].SkipReason, ShouldEqual, "dependency db is down: too many connections")
+		So(m.State()[0].Failures, ShouldEqual, 0)
+	})
+}
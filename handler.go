@@ -1,54 +1,127 @@
 package cron
 
 import (
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
 	"log/slog"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 	"time"
 
 	"github.com/robfig/cron/v3"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type State struct {
-	ID            int
+	ID            int    // robfig/cron entry id, depends on registration order
+	Key           string // stable job key, see Key
 	Name          string
+	Description   string   // see Description
+	Tags          []string // see Tags
 	Schedule      string
+	Spec          string // resolved schedule, e.g. with H tokens
 	IsMaintenance bool
 	LastState     string
+	Running       bool // job is running, overrun or stuck, see Manager.Cancel
 	LastErr       error
+	LastStack     string
 	LastDuration  time.Duration
+	AvgDuration   time.Duration // average duration of recent runs, see Manager.History
+	P95Duration   time.Duration // 95th percentile duration of recent runs
 	LastUpdatedAt time.Time
+	SkipReason    string
+	SkipKind      SkipKind // skip category, e.g. active or maintenance
+	LastMessage   string   // run summary, see SetRunMessage
+	Failures      int      // consecutive failures
+	Missed        int      // missed runs during downtime, see WithStore
 
 	LastRun time.Time
 	NextRun time.Time
+
+	Environment   string    // devel or production if job is environment-gated, see OnlyInDevel
+	Window        string    // allowed execution window, see OnlyBetween and MaintenanceWindow
+	WindowOpensAt time.Time // next window start if job is paused by window
+	Draining      bool      // manager is drained, see Manager.Drain
+	Paused        bool      // scheduling is paused, see Manager.PauseAll
+	Owner         string    // replica that owns the job on last run, see WithSharding
+	Disabled      bool      // scheduling is stopped by DisableAfterFailures or Manager.Disable
+	Breaker       string    // circuit breaker state if it is not closed, see WithCircuitBreaker
+	App           string    // app label of WithMetrics, known after the first run
+	Log           []string  // last log lines, see WithCapturedLog
 }
 
 type States []State
 
+// plainState is a State without json methods.
+type plainState State
+
+// stateJSON is a json representation of State with LastErr as string.
+type stateJSON struct {
+	plainState
+	LastErr string `json:",omitempty"`
+}
+
+func newStateJSON(s State) stateJSON {
+	v := stateJSON{plainState: plainState(s)}
+	if s.LastErr != nil {
+		v.LastErr = s.LastErr.Error()
+	}
+
+	return v
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s State) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newStateJSON(s))
+}
+
+// UnmarshalJSON implements json.Unmarshaler. LastErr is restored as a plain error.
+func (s *State) UnmarshalJSON(b []byte) error {
+	var v stateJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	*s = State(v.plainState)
+	s.LastErr = nil
+	if v.LastErr != "" {
+		s.LastErr = errors.New(v.LastErr)
+	}
+
+	return nil
+}
+
 // LogValue implements slog.LogValuer.
 func (s States) LogValue() slog.Value {
 	attrs := make([]slog.Attr, len(s))
 	for i, state := range s {
-		attrs[i] = slog.Group(
-			state.Name,
+		args := []any{
 			slog.String("schedule", state.Schedule),
 			slog.String("next", state.NextRun.Format(time.RFC3339)),
 			slog.String("state", state.LastState),
-		)
+		}
+		if state.LastState == string(stateFailed) && state.LastErr != nil {
+			args = append(args, slog.String("err", state.LastErr.Error()))
+		}
+		attrs[i] = slog.Group(state.Name, args...)
 	}
 	return slog.GroupValue(attrs...)
 }
 
 // State returns job states.
 func (cm *Manager) State() States {
-	cm.muState.Lock()
-	defer cm.muState.Unlock()
+	cm.muState.RLock()
+	defer cm.muState.RUnlock()
 
 	// get cron entries
 	entries := cm.cron.Entries()
@@ -58,72 +131,530 @@ func (cm *Manager) State() States {
 	}
 
 	// get cron jobs
+	now := cm.clock.Now()
 	rr := make([]State, len(cm.jobs))
 	for i, job := range cm.jobs {
-		s := State{
-			ID:            int(job.id),
-			Name:          job.name,
-			Schedule:      job.schedule.String(),
-			IsMaintenance: job.isMaintenance,
-			LastState:     string(job.last.state),
-			LastErr:       job.last.err,
-			LastDuration:  job.last.duration,
-			LastUpdatedAt: job.last.updatedAt,
-		}
+		rr[i] = cm.jobState(job, entryIndex[int(job.id)], now)
+	}
 
-		if e, ok := entryIndex[s.ID]; ok {
+	return rr
+}
+
+// jobState returns job state with its cron entry (zero entry for not scheduled job). It must be called under muState.
+func (cm *Manager) jobState(job *job, e cron.Entry, now time.Time) State {
+	s := State{
+		ID:            int(job.id),
+		Key:           job.key,
+		Name:          job.name,
+		Description:   job.description,
+		Tags:          slices.Clone(job.tags),
+		Schedule:      job.schedule.String(),
+		Spec:          job.spec,
+		IsMaintenance: job.isMaintenance,
+		LastState:     string(job.last.state),
+		Running:       job.last.state.isActive(),
+		LastErr:       job.last.err,
+		LastStack:     string(job.last.stack),
+		LastDuration:  job.last.duration,
+		LastUpdatedAt: job.last.updatedAt,
+		SkipReason:    job.last.reason,
+		SkipKind:      job.last.kind,
+		LastMessage:   job.last.message,
+		Failures:      job.last.failures,
+		Missed:        job.last.missed,
+		Environment:   job.env,
+		LastRun:       job.last.lastRun,
+		Draining:      cm.Draining(),
+		Paused:        cm.paused,
+		Owner:         job.owner,
+		Disabled:      job.autoDisabled,
+		Breaker:       job.breaker,
+		App:           job.metricsApp,
+		Log:           job.log.Lines(),
+	}
+
+	s.AvgDuration, s.P95Duration = cm.history.Stats(job.name)
+
+	if job.window != nil {
+		s.Window = job.window.String()
+		s.WindowOpensAt = job.window.opensAt(now)
+	} else if job.isMaintenance && cm.maintenanceWindow != nil {
+		s.Window = cm.maintenanceWindow.String()
+		s.WindowOpensAt = cm.maintenanceWindow.opensAt(now)
+	}
+
+	if e.Valid() {
+		if !e.Prev.IsZero() {
 			s.LastRun = e.Prev
-			s.NextRun = e.Next
 		}
+		s.NextRun = e.Next
 
-		rr[i] = s
+		// show next eligible run instead of holiday
+		if job.calendar != nil {
+			s.NextRun = nextWorkingRun(job.calendar, e.Schedule, e.Next)
+		}
 	}
 
-	return rr
+	return s
+}
+
+// Healthy returns false and names of unhealthy jobs: failed consecutively (see WithHealthThreshold) or
+// overdue by more than their interval.
+func (cm *Manager) Healthy() (bool, []string) {
+	var names []string
+	now := cm.clock.Now()
+	for _, st := range cm.State() {
+		if !cm.isHealthy(st, now) {
+			names = append(names, st.Name)
+		}
+	}
+
+	return len(names) == 0, names
+}
+
+// isHealthy checks job with Healthy rules.
+func (cm *Manager) isHealthy(s State, now time.Time) bool {
+	return s.Failures < cm.healthFailures && !cm.isOverdue(s, now)
+}
+
+// Summary is aggregate job counts for status badges, see Manager.Summary.
+type Summary struct {
+	Total    int
+	Running  int // running, waiting for a lock or queued
+	Disabled int
+	Errored  int  // last run failed
+	Overdue  int  // next run is in the past by more than schedule interval
+	Healthy  bool // see Manager.Healthy
+
+	PoolSize  int // workers of scheduled runs, see WithWorkerPool
+	PoolQueue int // scheduled runs waiting for a free worker
+}
+
+// Summary returns aggregate job counts computed from State.
+func (cm *Manager) Summary() Summary {
+	now := cm.clock.Now()
+	states := cm.State()
+	sm := Summary{Total: len(states), Healthy: true}
+	if cm.pool != nil {
+		sm.PoolSize, sm.PoolQueue = cm.pool.size, len(cm.pool.queue)
+	}
+	for _, st := range states {
+		switch s := cronState(st.LastState); {
+		case s.isActive(), s == stateWaiting, s == stateQueued:
+			sm.Running++
+		case st.Disabled, s == stateDisabled, s == stateUnscheduled:
+			sm.Disabled++
+		}
+
+		if st.LastErr != nil {
+			sm.Errored++
+		}
+		if cm.isOverdue(st, now) {
+			sm.Overdue++
+		}
+		sm.Healthy = sm.Healthy && cm.isHealthy(st, now)
+	}
+
+	return sm
+}
+
+// isOverdue checks if job next run is in the past by more than schedule interval.
+func (cm *Manager) isOverdue(s State, now time.Time) bool {
+	if s.NextRun.IsZero() || !s.NextRun.Before(now) {
+		return false
+	}
+
+	sch, err := cm.Schedule(s.Name)
+	if err != nil || sch == nil {
+		return false
+	}
+
+	interval := sch.Next(s.NextRun).Sub(s.NextRun)
+	return now.Sub(s.NextRun) > interval
 }
 
 func (cm *Manager) Handler(w http.ResponseWriter, r *http.Request) {
-	var (
-		err error
-		p   printer
-	)
-
-	startID := r.URL.Query().Get("start")
-	if startID != "" {
-		go func() { _ = cm.ManualRun(context.WithoutCancel(r.Context()), startID) }()
-		http.Redirect(w, r, r.URL.Path, http.StatusFound)
+	r, ok := cm.authorize(w, r)
+	if !ok {
+		return
+	}
+
+	var err error
+	p := printer{clock: cm.clock}
+
+	// state changes every second, auto-refresh must always get fresh state. Response depends on Accept header.
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Add("Vary", "Accept")
+
+	// compress responses, event stream is flushed per event and is not compressed
+	stream := strings.Contains(r.Header.Get("Accept"), "text/event-stream") || r.URL.Query().Get("stream") != ""
+	if cm.gzip && strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") && !stream {
+		gw := newGzipResponseWriter(w)
+		defer gw.Close()
+		w = gw
+	}
+
+	// run actions, GET is allowed only with WithManualRunGET for old links
+	if r.Method == http.MethodPost {
+		cm.actionHandler(w, r)
+		return
+	}
+	if startID := r.URL.Query().Get("start"); startID != "" {
+		if !cm.manualRunGET {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "use POST with csrf token to start a job", http.StatusMethodNotAllowed)
+			return
+		}
+
+		cm.startJob(w, r, startID, r.URL.Path, http.StatusFound)
+		return
+	}
+
+	// show planned activations
+	if r.URL.Query().Get("format") == "occurrences" {
+		hours, err := strconv.Atoi(r.URL.Query().Get("hours"))
+		if err != nil || hours <= 0 {
+			hours = 24
+		}
+
+		now := cm.clock.Now()
+		w.Header().Set("Content-Type", "application/json")
+		p.error(w, p.json(cm.Occurrences(now, now.Add(time.Duration(hours)*time.Hour)), w))
+		return
+	}
+
+	// show aggregate counts
+	if r.URL.Query().Get("summary") != "" {
+		w.Header().Set("Content-Type", "application/json")
+		p.error(w, p.json(cm.Summary(), w))
+		return
+	}
+
+	// show job details
+	if id := r.URL.Query().Get("job"); id != "" {
+		cm.jobHandler(w, r, id)
+		return
+	}
+
+	// stream state changes
+	if stream {
+		cm.streamEvents(w, r)
 		return
 	}
 
 	// show info
-	state := cm.State()
 	acceptHeader := r.Header.Get("Accept")
+	sq := newStateQuery(r.URL.Query())
+	state := sq.apply(cm.State())
 	switch {
 	case strings.Contains(acceptHeader, "application/json"):
 		w.Header().Set("Content-Type", "application/json")
 		err = p.json(state, w)
 	case strings.Contains(acceptHeader, "text/html"):
-		w.Header().Set("Content-Type", "text/html")
-		err = p.html(state, w)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		pg := page{States: state, Draining: cm.Draining(), Paused: cm.Paused(), Warnings: cm.Lint(), CSRF: csrfToken(w, r), Query: sq}
+		if cm.leader != nil {
+			pg.Leadership = "follower"
+			if cm.leader.IsLeader(r.Context()) {
+				pg.Leadership = "leader"
+			}
+		}
+		if cm.maintenanceWindow != nil {
+			pg.MaintenanceWindow = cm.maintenanceWindow.String()
+		}
+		if cm.pool != nil {
+			pg.PoolSize, pg.PoolQueue = cm.pool.size, len(cm.pool.queue)
+		}
+		if cm.htmlTemplate != nil {
+			err = p.custom(cm.htmlTemplate, pg, w)
+		} else {
+			err = p.html(htmlTemplate, pg, w)
+		}
 	default:
 		w.Header().Set("Content-Type", "text/plain")
-		p.text(state, w)
+		p.text(state, w, tabwriter.Debug)
 	}
 
 	p.error(w, err)
 }
 
+const (
+	principalKey contextKey = "principal"
+
+	csrfCookie   = "cron_csrf"
+	csrfTokenLen = 32 // hex of 16 random bytes
+)
+
+// actionHandler runs action from UI form: start=<key> starts the job. Form must have csrf token, see csrfToken.
+func (cm *Manager) actionHandler(w http.ResponseWriter, r *http.Request) {
+	if !validCSRF(r) {
+		http.Error(w, "invalid csrf token", http.StatusForbidden)
+		return
+	}
+
+	if id := r.PostFormValue("start"); id != "" {
+		cm.startJob(w, r, id, r.URL.RequestURI(), http.StatusSeeOther)
+		return
+	}
+	if action := r.FormValue("action"); action != "" {
+		cm.jobAction(w, r, action, r.FormValue("job"))
+		return
+	}
+
+	http.Redirect(w, r, r.URL.RequestURI(), http.StatusSeeOther)
+}
+
+// startJob starts job manually. By default run is detached from request and client is redirected to back url:
+// it is safe for long jobs. With wait=1 form or query value job runs synchronously with request context,
+// so it is cancelled if client disconnects, and response is the run result, e.g. for interactive debugging.
+func (cm *Manager) startJob(w http.ResponseWriter, r *http.Request, id, back string, code int) {
+	if r.FormValue("wait") == "" {
+		go func() { _ = cm.ManualRun(context.WithoutCancel(r.Context()), id) }()
+		http.Redirect(w, r, back, code)
+		return
+	}
+
+	err := cm.ManualRun(r.Context(), id)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrSkipped):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = fmt.Fprintln(w, "ok")
+	}
+}
+
+// jobAction enables or disables job scheduling or cancels running job and redirects back, so the page is rendered
+// with the new state. JSON clients get job state or error instead of redirect.
+func (cm *Manager) jobAction(w http.ResponseWriter, r *http.Request, action, id string) {
+	var err error
+	switch action {
+	case "enable":
+		err = cm.Enable(id)
+	case "disable":
+		err = cm.Disable(id)
+	case "cancel":
+		err = cm.Cancel(r.Context(), id)
+	default:
+		http.Error(w, "unknown action "+action, http.StatusBadRequest)
+		return
+	}
+
+	p, code := printer{clock: cm.clock}, http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, ErrNotRunning):
+		code = http.StatusConflict
+	}
+
+	if !strings.Contains(r.Header.Get("Accept"), "application/json") {
+		if err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
+
+		http.Redirect(w, r, r.URL.RequestURI(), http.StatusSeeOther)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		w.WriteHeader(code)
+		p.error(w, p.json(map[string]string{"error": err.Error()}, w))
+		return
+	}
+
+	d, _ := cm.jobDetail(id)
+	p.error(w, p.json(d.State, w))
+}
+
+// csrfToken returns token for action forms from cookie or sets new one (double submit cookie).
+func csrfToken(w http.ResponseWriter, r *http.Request) string {
+	if c, err := r.Cookie(csrfCookie); err == nil && len(c.Value) == csrfTokenLen {
+		return c.Value
+	}
+
+	token := newRunID()
+	http.SetCookie(w, &http.Cookie{Name: csrfCookie, Value: token, Path: r.URL.Path, HttpOnly: true, SameSite: http.SameSiteStrictMode})
+	return token
+}
+
+// validCSRF checks that csrf form value matches csrf cookie.
+func validCSRF(r *http.Request) bool {
+	c, err := r.Cookie(csrfCookie)
+	if err != nil || len(c.Value) != csrfTokenLen {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(c.Value), []byte(r.PostFormValue("csrf"))) == 1
+}
+
+// HandlerAuth sets authorization hook for Handler and EventsHandler: request is rejected with 403 if fn returns false.
+// Principal is passed to manual runs via context (see PrincipalFromContext): it is logged by WithLogger and WithSLog
+// and recorded in run history.
+func HandlerAuth(fn func(r *http.Request) (principal string, ok bool)) Option {
+	return func(cm *Manager) {
+		cm.handlerAuth = fn
+		cm.authChallenge = ""
+	}
+}
+
+// HandlerBasicAuth protects Handler with HTTP Basic Auth, it is a HandlerAuth hook with username as principal.
+// passwordHash is bcrypt hash of password, e.g. from `htpasswd -nbB admin secret` (part after colon).
+// Request is rejected with 401 and WWW-Authenticate challenge on failure.
+func HandlerBasicAuth(user, passwordHash string) Option {
+	return func(cm *Manager) {
+		hash := []byte(passwordHash)
+		if _, err := bcrypt.Cost(hash); err != nil {
+			cm.err = fmt.Errorf("invalid basic auth password hash: want bcrypt: %w", err)
+			return
+		}
+
+		userHash := sha256.Sum256([]byte(user))
+		HandlerAuth(func(r *http.Request) (string, bool) {
+			u, p, ok := r.BasicAuth()
+			if !ok {
+				return "", false
+			}
+
+			// check password for wrong user too to avoid timing difference between wrong user and wrong password
+			uh := sha256.Sum256([]byte(u))
+			validUser := subtle.ConstantTimeCompare(uh[:], userHash[:]) == 1
+			validPassword := bcrypt.CompareHashAndPassword(hash, []byte(p)) == nil
+			if !validUser || !validPassword {
+				return "", false
+			}
+
+			return u, true
+		})(cm)
+		cm.authChallenge = `Basic realm="cron", charset="UTF-8"`
+	}
+}
+
+// authorize checks request with HandlerAuth hook and returns request with principal in context.
+func (cm *Manager) authorize(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	if cm.handlerAuth == nil {
+		return r, true
+	}
+
+	principal, ok := cm.handlerAuth(r)
+	if !ok && cm.authChallenge != "" {
+		w.Header().Set("WWW-Authenticate", cm.authChallenge)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return r, false
+	} else if !ok {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return r, false
+	}
+	if principal != "" {
+		r = r.WithContext(NewPrincipalContext(r.Context(), principal))
+	}
+
+	return r, true
+}
+
+// NewPrincipalContext creates new context with principal who started manual run, see HandlerAuth.
+func NewPrincipalContext(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// PrincipalFromContext returns principal who started manual run, see HandlerAuth.
+func PrincipalFromContext(ctx context.Context) string {
+	principal, _ := ctx.Value(principalKey).(string)
+	return principal
+}
+
+// WithManualRunGET allows manual runs by GET request with ?start=<key> for internal tools which depend on it.
+// By default manual runs require POST with csrf token, so crawlers and link previews can't start jobs.
+func WithManualRunGET() Option {
+	return func(cm *Manager) {
+		cm.manualRunGET = true
+	}
+}
+
+// jobHandler shows job details page in json or html format.
+func (cm *Manager) jobHandler(w http.ResponseWriter, r *http.Request, id string) {
+	var err error
+	p := printer{clock: cm.clock}
+
+	d, ok := cm.jobDetail(id)
+	if !ok {
+		http.Error(w, ErrNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "text/html") {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err = p.html(jobTemplate, jobPage{JobDetail: d, CSRF: csrfToken(w, r)}, w)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		err = p.json(d, w)
+	}
+
+	p.error(w, err)
+}
+
+// jobPage is a job details page data.
+type jobPage struct {
+	JobDetail
+	CSRF string // token for action forms
+}
+
+// JobDetail is a job state with recent runs and upcoming activations.
+type JobDetail struct {
+	State
+	History  []RunRecord
+	NextRuns []time.Time
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d JobDetail) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		stateJSON
+		History  []RunRecord
+		NextRuns []time.Time
+	}{newStateJSON(d.State), d.History, d.NextRuns})
+}
+
+// jobDetail returns job details by key or name.
+func (cm *Manager) jobDetail(id string) (JobDetail, bool) {
+	idx := cm.jobIndex(id)
+	if idx < 0 {
+		return JobDetail{}, false
+	}
+
+	now := cm.clock.Now()
+	d := JobDetail{State: cm.State()[idx], History: cm.History(cm.jobs[idx].name)}
+	for _, o := range cm.jobOccurrences(cm.jobs[idx], now, now.AddDate(1, 0, 0), detailNextRuns) {
+		d.NextRuns = append(d.NextRuns, o.Time)
+	}
+
+	return d, true
+}
+
 // TextSchedule writes current cron schedule with TabWriter.
 func (cm *Manager) TextSchedule(w io.Writer) {
-	printer{}.text(cm.State(), w)
+	printer{clock: cm.clock}.text(cm.State(), w, tabwriter.Debug)
+}
+
+// TextSchedulePlain writes current cron schedule as aligned columns without separators.
+func (cm *Manager) TextSchedulePlain(w io.Writer) {
+	printer{clock: cm.clock}.text(cm.State(), w, 0)
 }
 
 // printer is a helper to prints state in json,html or text format.
-type printer struct{}
+type printer struct {
+	clock Clock
+}
 
-// json writes states as json.
-func (printer) json(state []State, w io.Writer) error {
-	return json.NewEncoder(w).Encode(state)
+// json writes value as json.
+func (printer) json(v any, w io.Writer) error {
+	return json.NewEncoder(w).Encode(v)
 }
 
 // error writes 500 http status code and error if not nil.
@@ -134,23 +665,35 @@ func (printer) error(w http.ResponseWriter, err error) {
 	}
 }
 
-// text writes states with TabWriter.
-func (printer) text(state []State, w io.Writer) {
-	wr := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.Debug)
-	fmt.Fprint(wr, tableRow("cron", "schedule", "next", "state"))
+// text writes states with TabWriter. Cells are padded for tabwriter.Debug bars.
+func (p printer) text(state []State, w io.Writer, flags uint) {
+	row := tableRow
+	if flags&tabwriter.Debug == 0 {
+		row = func(ss ...string) string { return strings.Join(ss, "\t") + "\n" }
+	}
+
+	wr := tabwriter.NewWriter(w, 0, 0, 2, ' ', flags)
+	fmt.Fprint(wr, row("cron", "schedule", "next", "duration", "state"))
 	for _, st := range state {
-		next, maintenance := "", ""
-		if st.NextRun.IsZero() {
-			next = "never"
-		} else {
-			next = fmt.Sprintf("(starts in %s)", time.Until(st.NextRun))
+		next, duration, maintenance := "never", "-", ""
+		if !st.NextRun.IsZero() {
+			next = fmt.Sprintf("(starts in %s)", st.NextRun.Sub(p.clock.Now()).Round(time.Second))
+		}
+
+		if st.LastDuration > 0 {
+			duration = st.LastDuration.Round(time.Millisecond).String()
 		}
 
 		if st.IsMaintenance {
 			maintenance = " (maintenance)"
 		}
 
-		fmt.Fprintf(wr, tableRow("cron=%s%s", "%s", "%s", "%s"), st.Name, maintenance, st.Schedule, next, st.LastState)
+		schedule := st.Schedule
+		if st.Spec != "" && st.Spec != st.Schedule {
+			schedule += " (" + st.Spec + ")"
+		}
+
+		fmt.Fprintf(wr, row("cron=%s%s", "%s", "%s", "%s", "%s"), st.Name, maintenance, schedule, next, duration, st.LastState)
 	}
 	_ = wr.Flush()
 }
@@ -163,15 +706,95 @@ func tableRow(ss ...string) string {
 	return strings.Join(ss, "\t") + "\n"
 }
 
-// html renders cron UI.
-func (printer) html(state []State, w io.Writer) error {
-	tmpl, err := template.New("states").Funcs(template.FuncMap{
+// page is a data for cron UI.
+type page struct {
+	States            []State
+	MaintenanceWindow string
+	Draining          bool
+	Paused            bool
+	Leadership        string // leader or follower, see WithLeader
+	PoolSize          int    // see WithWorkerPool
+	PoolQueue         int
+	Warnings          []Warning
+	CSRF              string // token for action forms, send it as csrf form value
+	Query             stateQuery
+}
+
+// WithGzip enables gzip compression of Handler responses for clients with "Accept-Encoding: gzip" header.
+// It is disabled by default to avoid double compression behind a proxy.
+func WithGzip() Option {
+	return func(cm *Manager) {
+		cm.gzip = true
+	}
+}
+
+// gzipResponseWriter is a http.ResponseWriter with gzip compressed body.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	gz *gzip.Writer
+}
+
+func newGzipResponseWriter(w http.ResponseWriter) *gzipResponseWriter {
+	w.Header().Set("Content-Encoding", "gzip")
+	w.Header().Add("Vary", "Accept-Encoding")
+	return &gzipResponseWriter{ResponseWriter: w, gz: gzip.NewWriter(w)}
+}
+
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	return w.gz.Write(b)
+}
+
+// Close flushes compressed body.
+func (w *gzipResponseWriter) Close() error {
+	return w.gz.Close()
+}
+
+// WithHTMLTemplate replaces built-in UI template of Handler. Template receives page data with fields
+// States []State, MaintenanceWindow string, Draining bool, Paused bool, Leadership string, PoolSize int, PoolQueue int,
+// Warnings []Warning, CSRF string and Query with Sort, Order, Filter, Q fields and SortURL, FilterURL, SortMark methods.
+// Parse it with TemplateFuncs to use built-in helpers (formatTime, formatDuration, stateColor, formatName, formatNextRun,
+// isOverdue), {{template "style"}} for built-in styles, {{template "run" (runForm .Key $.CSRF)}} for Run button,
+// {{template "toggle" (toggleForm . $.CSRF)}} for Enable or Disable button and {{template "cancel" (runForm .Key $.CSRF)}}
+// for Cancel button.
+func WithHTMLTemplate(tmpl *template.Template) Option {
+	return func(cm *Manager) {
+		cm.htmlTemplate = tmpl
+	}
+}
+
+// TemplateFuncs returns helper funcs of the built-in UI template for parsing custom templates, see WithHTMLTemplate.
+func TemplateFuncs() template.FuncMap {
+	return printer{clock: realClock{}}.funcs()
+}
+
+// funcs returns template helper funcs bound to printer clock.
+func (p printer) funcs() template.FuncMap {
+	return template.FuncMap{
+		"list": func(v ...string) []string { return v },
+		"runForm": func(key, csrf string) map[string]string {
+			return map[string]string{"Key": key, "CSRF": csrf}
+		},
+		"toggleForm": func(s State, csrf string) map[string]string {
+			switch {
+			case !Schedule(s.Schedule).IsActive():
+				return nil // "disabled" or empty schedule is changed only by Reschedule
+			case s.Disabled:
+				return map[string]string{"Key": s.Key, "CSRF": csrf, "Action": "enable", "Label": "Enable"}
+			default:
+				return map[string]string{"Key": s.Key, "CSRF": csrf, "Action": "disable", "Label": "Disable"}
+			}
+		},
 		"formatTime": func(t time.Time) string {
 			if t.IsZero() {
 				return ""
 			}
 			r := t.Format("2006-01-02 15:04:05")
-			d := time.Since(t)
+			d := p.clock.Since(t)
 			if d > 0 {
 				r += fmt.Sprintf(" (%s ago)", d.Round(time.Second).String())
 			} else {
@@ -189,12 +812,20 @@ func (printer) html(state []State, w io.Writer) error {
 			switch state {
 			case "running":
 				return "background-color: #e6f7ff"
-			case "disabled":
+			case "disabled", "unscheduled":
 				return "background-color: #f5f5f5"
 			case "skipped":
 				return "background-color: #fff7e6"
-			case "idle":
+			case "idle", "completed":
 				return "background-color: #e6ffed"
+			case "waiting", "queued":
+				return "background-color: #f9f0ff"
+			case "dry-run":
+				return "background-color: #fcffe6"
+			case "stuck":
+				return "background-color: #ffccc7"
+			case "overrun", "failed":
+				return "background-color: #fff1f0"
 			default:
 				return ""
 			}
@@ -209,7 +840,7 @@ func (printer) html(state []State, w io.Writer) error {
 			if nextRun.IsZero() {
 				return ""
 			}
-			duration := time.Until(nextRun)
+			duration := nextRun.Sub(p.clock.Now())
 			if duration < 0 {
 				return "overdue"
 			}
@@ -217,21 +848,236 @@ func (printer) html(state []State, w io.Writer) error {
 				" (in " + duration.Round(time.Second).String() + ")"
 		},
 		"isOverdue": func(nextRun time.Time) bool {
-			return !nextRun.IsZero() && nextRun.Before(time.Now())
+			return !nextRun.IsZero() && nextRun.Before(p.clock.Now())
 		},
-	}).Parse(htmlTemplate)
+	}
+}
+
+// html renders cron UI page with html template.
+func (p printer) html(text string, data any, w io.Writer) error {
+	tmpl, err := template.New("page").Funcs(p.funcs()).Parse(htmlStyle + htmlForms)
+	if err != nil {
+		return err
+	}
+
+	if tmpl, err = tmpl.Parse(text); err != nil {
+		return err
+	}
+
+	return tmpl.Execute(w, data)
+}
+
+// custom renders cron UI page with custom template. Helper funcs are rebound to printer clock
+// and built-in "style" template is added if the template doesn't define it.
+func (p printer) custom(tmpl *template.Template, data any, w io.Writer) error {
+	tmpl, err := tmpl.Clone()
 	if err != nil {
 		return err
 	}
 
-	return tmpl.Execute(w, state)
+	tmpl.Funcs(p.funcs())
+	if tmpl.Lookup("style") == nil {
+		if _, err = tmpl.Parse(htmlStyle); err != nil {
+			return err
+		}
+	}
+	if tmpl.Lookup("run") == nil {
+		if _, err = tmpl.Parse(htmlForms); err != nil {
+			return err
+		}
+	}
+
+	return tmpl.Execute(w, data)
 }
 
 const htmlTemplate = `<!DOCTYPE html>
 <html>
 <head>
     <title>Cron Tasks Status</title>
+    <noscript><meta http-equiv="refresh" content="10"></noscript>
+    {{template "style"}}
+</head>
+<body>
+    <div id="content">
+    <h1>Cron Tasks Status</h1>
+    {{if .Draining}}<p class="overdue">Draining: scheduled runs are skipped</p>{{end}}
+    {{if .Paused}}<p class="overdue">Paused: scheduling is suppressed</p>{{end}}
+    {{if .Leadership}}<p>Leadership: {{.Leadership}}{{if eq .Leadership "follower"}}, scheduled runs are skipped{{end}}</p>{{end}}
+    {{if .MaintenanceWindow}}<p>Maintenance window: {{.MaintenanceWindow}}</p>{{end}}
+    {{if .PoolSize}}<p>Worker pool: {{.PoolSize}} workers, {{.PoolQueue}} queued</p>{{end}}
+    {{range .Warnings}}<p class="overdue">Warning: {{.Job}}: {{.Message}}</p>{{end}}
+    <form method="get" class="filter">
+        {{with .Query}}
+        Show:
+        {{range $f := (list "" "running" "failed" "maintenance" "disabled")}}
+        {{if eq $f $.Query.Filter}}<b>{{or $f "all"}}</b>{{else}}<a href="{{$.Query.FilterURL $f}}" class="action-link">{{or $f "all"}}</a>{{end}}
+        {{end}}
+        {{if .Sort}}<input type="hidden" name="sort" value="{{.Sort}}">{{end}}
+        {{if .Order}}<input type="hidden" name="order" value="{{.Order}}">{{end}}
+        {{if .Filter}}<input type="hidden" name="filter" value="{{.Filter}}">{{end}}
+        <input type="search" name="q" value="{{.Q}}" placeholder="name">
+        {{end}}
+    </form>
+    <table>
+        <thead>
+            <tr>
+                <th>ID</th>
+                <th><a href="{{.Query.SortURL "name"}}" class="action-link">Name{{.Query.SortMark "name"}}</a></th>
+                <th>Schedule</th>
+                <th><a href="{{.Query.SortURL "state"}}" class="action-link">State{{.Query.SortMark "state"}}</a></th>
+                <th>Last Error</th>
+                <th><a href="{{.Query.SortURL "duration"}}" class="action-link">Duration{{.Query.SortMark "duration"}}</a></th>
+                <th>Updated</th>
+                <th>Last Run</th>
+                <th><a href="{{.Query.SortURL "next"}}" class="action-link">Next Run{{.Query.SortMark "next"}}</a></th>
+                <th>Action</th>
+            </tr>
+        </thead>
+        <tbody>
+            {{range .States}}
+            <tr style="{{.LastState | stateColor}}">
+                <td>{{.ID}}</td>
+                <td>
+                    <a href="?job={{.Key}}" class="action-link"{{if .Description}} title="{{.Description}}"{{end}}>{{ formatName .Name .IsMaintenance}}</a>
+                    {{if .Environment}}<br><small>{{.Environment}} only</small>{{end}}
+                    {{if .Owner}}<br><small>owner {{.Owner}}</small>{{end}}
+                </td>
+                <td class="center">
+                    {{.Schedule}}
+                    {{if and .Spec (ne .Spec .Schedule)}}<br><small>{{.Spec}}</small>{{end}}
+                    {{if .Window}}<br><small>window {{.Window}}</small>{{end}}
+                </td>
+                <td class="center">
+                    {{.LastState}}
+                    {{if .Breaker}}<br><small class="overdue">breaker {{.Breaker}}</small>{{end}}
+                    {{if not .WindowOpensAt.IsZero}}<br><small>paused until {{.WindowOpensAt.Format "15:04"}}</small>{{end}}
+                </td>
+                <td>{{if .LastErr}}{{.LastErr.Error}}{{else if .SkipReason}}{{.SkipReason}}{{else}}{{.LastMessage}}{{end}}</td>
+                <td class="right"{{if .AvgDuration}} title="avg {{.AvgDuration | formatDuration}}, p95 {{.P95Duration | formatDuration}}"{{end}}>{{.LastDuration | formatDuration}}</td>
+                <td>{{.LastUpdatedAt | formatTime}}</td>
+                <td>
+                    {{.LastRun | formatTime}}
+                    {{if .Missed}}<br><small class="overdue">missed {{.Missed}}</small>{{end}}
+                </td>
+                <td {{if isOverdue .NextRun}}class="overdue"{{end}}>
+                    {{formatNextRun .NextRun}}
+                </td>
+                <td>
+                    {{template "run" (runForm .Key $.CSRF)}} {{template "toggle" (toggleForm . $.CSRF)}}
+                    {{if .Running}}{{template "cancel" (runForm .Key $.CSRF)}}{{end}}
+                </td>
+            </tr>
+            {{if .LastStack}}
+            <tr class="detail">
+                <td colspan="10">
+                    <details>
+                        <summary>Stack trace</summary>
+                        <pre>{{.LastStack}}</pre>
+                    </details>
+                </td>
+            </tr>
+            {{end}}
+            {{end}}
+        </tbody>
+    </table>
+    </div>
+    <script>
+        // reload content in place on state changes (see EventsHandler) at most once per second
+        // or every 10s while stream is not available
+        const refresh = () => {
+            fetch(location.href, {headers: {Accept: "text/html"}})
+                .then(r => r.text())
+                .then(html => {
+                    const doc = new DOMParser().parseFromString(html, "text/html");
+                    document.getElementById("content").replaceWith(doc.getElementById("content"));
+                });
+        };
+        let fallback, pending;
+        const changed = () => {
+            pending = pending || setTimeout(() => {
+                pending = null;
+                refresh();
+            }, 1000);
+        };
+        if (window.EventSource) {
+            const es = new EventSource(location.pathname + "?stream=changed");
+            es.onmessage = changed;
+            es.onopen = () => clearInterval(fallback);
+            es.onerror = () => {
+                clearInterval(fallback);
+                fallback = setInterval(refresh, 10000);
+            };
+        } else {
+            fallback = setInterval(refresh, 10000);
+        }
+    </script>
+</body>
+</html>`
+
+const jobTemplate = `<!DOCTYPE html>
+<html>
+<head>
+    <title>{{.Name}} - Cron Tasks Status</title>
     <meta http-equiv="refresh" content="10">
+    {{template "style"}}
+</head>
+<body>
+    <p><a href="?" class="action-link">&larr; All jobs</a></p>
+    <h1>{{ formatName .Name .IsMaintenance}}</h1>
+    {{if .Description}}<p>{{.Description}}</p>{{end}}
+    <table>
+        {{if .Tags}}<tr><th>Tags</th><td>{{range $i, $t := .Tags}}{{if $i}}, {{end}}{{$t}}{{end}}</td></tr>{{end}}
+        <tr><th>Schedule</th><td>{{.Schedule}}{{if and .Spec (ne .Spec .Schedule)}} ({{.Spec}}){{end}}</td></tr>
+        {{if .Window}}<tr><th>Window</th><td>{{.Window}}</td></tr>{{end}}
+        {{if .Owner}}<tr><th>Owner</th><td>{{.Owner}}</td></tr>{{end}}
+        {{if .Environment}}<tr><th>Environment</th><td>{{.Environment}} only</td></tr>{{end}}
+        <tr style="{{.LastState | stateColor}}"><th>State</th><td>{{.LastState}}</td></tr>
+        {{if .Breaker}}<tr><th>Circuit breaker</th><td>{{.Breaker}}</td></tr>{{end}}
+        {{if .SkipReason}}<tr><th>Skip reason</th><td>{{if .SkipKind}}{{.SkipKind}}: {{end}}{{.SkipReason}}</td></tr>{{end}}
+        {{if .LastMessage}}<tr><th>Message</th><td>{{.LastMessage}}</td></tr>{{end}}
+        <tr><th>Duration</th><td>{{.LastDuration | formatDuration}}{{if .AvgDuration}}, avg {{.AvgDuration | formatDuration}}, p95 {{.P95Duration | formatDuration}}{{end}}</td></tr>
+        <tr><th>Updated</th><td>{{.LastUpdatedAt | formatTime}}</td></tr>
+        <tr><th>Last Run</th><td>{{.LastRun | formatTime}}</td></tr>
+        <tr><th>Failures</th><td>{{.Failures}}{{if .Missed}}, missed {{.Missed}}{{end}}</td></tr>
+        <tr><th>Action</th><td>{{template "run" (runForm .Key .CSRF)}} {{template "toggle" (toggleForm .State .CSRF)}}{{if .Running}} {{template "cancel" (runForm .Key .CSRF)}}{{end}}</td></tr>
+    </table>
+
+    {{if .LastErr}}
+    <h2>Last Error</h2>
+    <pre>{{.LastErr.Error}}</pre>
+    {{end}}
+    {{if .LastStack}}
+    <h2>Stack trace</h2>
+    <pre>{{.LastStack}}</pre>
+    {{end}}
+
+    {{if .Log}}
+    <h2>Log</h2>
+    <pre>{{range .Log}}{{.}}
+{{end}}</pre>
+    {{end}}
+
+    <h2>Recent Runs</h2>
+    <table>
+        <tr><th>Started</th><th>Duration</th><th>State</th><th>Error</th></tr>
+        {{range .History}}
+        <tr style="{{if eq .State "error"}}background-color: #fff1f0{{else if eq .State "skipped"}}background-color: #fff7e6{{end}}">
+            <td>{{formatTime .StartedAt}}{{if .Manual}} <small>manual{{if .Principal}} by {{.Principal}}{{end}}</small>{{end}}</td>
+            <td class="right">{{.Duration | formatDuration}}</td>
+            <td>{{.State}}</td>
+            <td>{{.Err}}</td>
+        </tr>
+        {{else}}<tr><td colspan="4">no runs yet</td></tr>{{end}}
+    </table>
+
+    <h2>Next Runs</h2>
+    <table>
+        {{range .NextRuns}}<tr><td>{{formatTime .}}</td></tr>{{else}}<tr><td>never</td></tr>{{end}}
+    </table>
+</body>
+</html>`
+
+const htmlStyle = `{{define "style"}}
     <style>
         body {
             font-family: Arial, sans-serif;
@@ -268,47 +1114,34 @@ const htmlTemplate = `<!DOCTYPE html>
         .action-link:hover {
             text-decoration: underline;
         }
+        form.filter {
+            margin-top: 20px;
+        }
+        form.action {
+            display: inline;
+            margin: 0;
+        }
+        form.action button {
+            background: none;
+            border: none;
+            padding: 0;
+            font: inherit;
+            cursor: pointer;
+        }
+        tr.detail pre {
+            font-size: 12px;
+            white-space: pre-wrap;
+        }
         .overdue {
             color: #d32f2f;
             font-weight: bold;
         }
     </style>
-</head>
-<body>
-    <h1>Cron Tasks Status</h1>
-    <table>
-        <thead>
-            <tr>
-                <th>ID</th>
-                <th>Name</th>
-                <th>Schedule</th>
-                <th>State</th>
-                <th>Last Error</th>
-                <th>Duration</th>
-                <th>Updated</th>
-                <th>Last Run</th>
-                <th>Next Run</th>
-                <th>Action</th>
-            </tr>
-        </thead>
-        <tbody>
-            {{range .}}
-            <tr style="{{.LastState | stateColor}}">
-                <td>{{.ID}}</td>
-                <td>{{ formatName .Name .IsMaintenance}}</td>
-                <td class="center">{{.Schedule}}</td>
-                <td class="center">{{.LastState}}</td>
-                <td>{{if .LastErr}}{{.LastErr.Error}}{{end}}</td>
-                <td class="right">{{.LastDuration | formatDuration}}</td>
-                <td>{{.LastUpdatedAt | formatTime}}</td>
-                <td>{{.LastRun | formatTime}}</td>
-                <td {{if isOverdue .NextRun}}class="overdue"{{end}}>
-                    {{formatNextRun .NextRun}}
-                </td>
-                <td><a href="?start={{.Name}}" class="action-link">Run</a></td>
-            </tr>
-            {{end}}
-        </tbody>
-    </table>
-</body>
-</html>`
+{{end}}`
+
+// htmlForms are action forms with csrf token, e.g. {{template "run" (runForm .Key $.CSRF)}} and
+// {{template "toggle" (toggleForm . $.CSRF)}} for Enable or Disable button, {{template "cancel" (runForm .Key $.CSRF)}}
+// for Cancel button of running job.
+const htmlForms = `{{define "run"}}<form method="post" class="action"><input type="hidden" name="csrf" value="{{.CSRF}}"><button name="start" value="{{.Key}}" class="action-link">Run</button></form>{{end}}` +
+	`{{define "toggle"}}{{if .}}<form method="post" class="action"><input type="hidden" name="csrf" value="{{.CSRF}}"><input type="hidden" name="job" value="{{.Key}}"><button name="action" value="{{.Action}}" class="action-link">{{.Label}}</button></form>{{end}}{{end}}` +
+	`{{define "cancel"}}<form method="post" class="action"><input type="hidden" name="csrf" value="{{.CSRF}}"><input type="hidden" name="job" value="{{.Key}}"><button name="action" value="cancel" class="action-link">Cancel</button></form>{{end}}`
@@ -0,0 +1,195 @@
+package cron
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	manualKey contextKey = "manual"
+
+	// defaultHistoryDepth is a number of runs per job kept in memory.
+	defaultHistoryDepth = 50
+)
+
+// RunRecord is a record of one job run.
+type RunRecord struct {
+	ID        string // unique run id
+	Manager   string // manager name, see WithName
+	Job       string
+	StartedAt time.Time
+	Duration  time.Duration
+	State     string // ok, error or skipped
+	Err       string
+	Manual    bool
+	Principal string // who started manual run, see HandlerAuth
+}
+
+// HistorySink receives a record after every run, e.g. to store it in SQL or ClickHouse.
+type HistorySink interface {
+	Record(ctx context.Context, r RunRecord) error
+}
+
+// WithHistory sends run records to sink. Sink errors are logged via WithManagerLogger and don't affect job result.
+// Manager keeps recent runs in memory regardless of sink, see Manager.History.
+func WithHistory(sink HistorySink) Option {
+	return func(cm *Manager) {
+		cm.historySink = sink
+	}
+}
+
+// WithHistoryDepth sets number of recent runs per job kept in memory for Manager.History and job page, default is 50.
+func WithHistoryDepth(n int) Option {
+	return func(cm *Manager) {
+		if n < 1 {
+			cm.err = fmt.Errorf("invalid history depth=%d", n)
+			return
+		}
+
+		cm.history = NewMemoryHistory(n)
+	}
+}
+
+// MemoryHistory is a HistorySink that keeps last depth records per job.
+type MemoryHistory struct {
+	mu    sync.RWMutex
+	depth int
+	runs  map[string][]RunRecord
+	stats map[string]durationStats // computed on Record, see Stats
+}
+
+// durationStats are aggregate durations of job runs.
+type durationStats struct {
+	avg, p95 time.Duration
+}
+
+// NewMemoryHistory returns new in-memory HistorySink with depth records per job.
+func NewMemoryHistory(depth int) *MemoryHistory {
+	return &MemoryHistory{depth: depth, runs: make(map[string][]RunRecord), stats: make(map[string]durationStats)}
+}
+
+// Record implements HistorySink.
+func (h *MemoryHistory) Record(_ context.Context, r RunRecord) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rr := append(h.runs[r.Job], r)
+	if len(rr) > h.depth {
+		rr = rr[len(rr)-h.depth:]
+	}
+	h.runs[r.Job] = rr
+	h.stats[r.Job] = newDurationStats(rr)
+
+	return nil
+}
+
+// reset removes all records.
+func (h *MemoryHistory) reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.runs, h.stats = make(map[string][]RunRecord), make(map[string]durationStats)
+}
+
+// Stats returns average and 95th percentile duration of stored job runs. Skipped runs are excluded.
+func (h *MemoryHistory) Stats(job string) (avg, p95 time.Duration) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	st := h.stats[job]
+	return st.avg, st.p95
+}
+
+// newDurationStats computes durations stats of runs except skipped ones.
+func newDurationStats(rr []RunRecord) durationStats {
+	dd := make([]time.Duration, 0, len(rr))
+	var sum time.Duration
+	for _, r := range rr {
+		if r.State != "skipped" {
+			dd = append(dd, r.Duration)
+			sum += r.Duration
+		}
+	}
+	if len(dd) == 0 {
+		return durationStats{}
+	}
+
+	// nearest-rank percentile
+	slices.Sort(dd)
+	return durationStats{
+		avg: sum / time.Duration(len(dd)),
+		p95: dd[(len(dd)*95+99)/100-1],
+	}
+}
+
+// Runs returns job records, newest first.
+func (h *MemoryHistory) Runs(job string) []RunRecord {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	rr := h.runs[job]
+	res := make([]RunRecord, len(rr))
+	for i := range rr {
+		res[len(rr)-1-i] = rr[i]
+	}
+
+	return res
+}
+
+// History returns recent runs of the job, newest first.
+func (cm *Manager) History(name string) []RunRecord {
+	return cm.history.Runs(name)
+}
+
+// recordRun saves run record to in-memory history and sink.
+func (cm *Manager) recordRun(ctx context.Context, name string, startedAt time.Time, err error) {
+	r := RunRecord{
+		ID:        newRunID(),
+		Manager:   cm.name,
+		Job:       name,
+		StartedAt: startedAt,
+		Duration:  cm.clock.Since(startedAt),
+		State:     "ok",
+		Manual:    isManualFromContext(ctx),
+		Principal: PrincipalFromContext(ctx),
+	}
+
+	switch {
+	case errors.Is(err, ErrSkipped):
+		r.State, r.Err = "skipped", err.Error()
+	case err != nil:
+		r.State, r.Err = "error", err.Error()
+	}
+
+	_ = cm.history.Record(ctx, r)
+	if cm.historySink == nil {
+		return
+	}
+
+	if err := cm.historySink.Record(context.WithoutCancel(ctx), r); err != nil && cm.logger != nil {
+		cm.logger.Error(ctx, "cron history record failed", "job", name, "err", err)
+	}
+}
+
+// newRunID returns random run id.
+func newRunID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// newManualContext marks run as manual, see ManualRun.
+func newManualContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, manualKey, true)
+}
+
+func isManualFromContext(ctx context.Context) bool {
+	v, _ := ctx.Value(manualKey).(bool)
+	return v
+}
@@ -0,0 +1,102 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type testHistorySink struct {
+	records []RunRecord
+	err     error
+}
+
+func (s *testHistorySink) Record(_ context.Context, r RunRecord) error {
+	s.records = append(s.records, r)
+	return s.err
+}
+
+func TestManager_History(t *testing.T) {
+	Convey("Test run history", t, func() {
+		clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 30, 0, time.UTC)}
+		sink := &testHistorySink{err: errors.New("sink failed")}
+		lg := &testLogger{}
+		m := NewManager(WithName("test"), WithClock(clock), WithManualTicker(), WithHistory(sink), WithManagerLogger(lg))
+
+		var fail bool
+		m.AddFunc("f1", "* * * * *", func(context.Context) error {
+			clock.Add(time.Second)
+			if fail {
+				return errors.New("failed")
+			}
+			return nil
+		})
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		clock.Add(time.Minute)
+		So(m.Tick(t.Context(), clock.Now()), ShouldBeNil)
+		fail = true
+		So(m.ManualRun(t.Context(), "f1"), ShouldNotBeNil)
+
+		So(sink.records, ShouldHaveLength, 2)
+		So(sink.records[0].ID, ShouldNotEqual, sink.records[1].ID)
+		msg, _ := lg.last()
+		So(msg, ShouldEqual, "cron history record failed")
+
+		rr := m.History("f1")
+		So(rr, ShouldHaveLength, 2)
+		So(rr[0].Manual, ShouldBeTrue)
+		So(rr[0].State, ShouldEqual, "error")
+		So(rr[0].Err, ShouldEqual, "failed")
+		So(rr[1], ShouldResemble, RunRecord{
+			ID:        sink.records[0].ID,
+			Manager:   "test",
+			Job:       "f1",
+			StartedAt: time.Date(2025, 1, 1, 12, 1, 30, 0, time.UTC),
+			Duration:  time.Second,
+			State:     "ok",
+		})
+
+		Convey("Test memory history depth", func() {
+			h := NewMemoryHistory(2)
+			for i := range 3 {
+				So(h.Record(t.Context(), RunRecord{Job: "f1", ID: string(rune('a' + i))}), ShouldBeNil)
+			}
+			rr := h.Runs("f1")
+			So(rr, ShouldHaveLength, 2)
+			So(rr[0].ID, ShouldEqual, "c")
+			So(rr[1].ID, ShouldEqual, "b")
+		})
+
+		Convey("Test manager history depth", func() {
+			m := NewManager(WithHistoryDepth(2))
+			m.AddFunc("f1", "", newCronFunc("f1"))
+			So(m.Run(t.Context()), ShouldBeNil)
+			for range 3 {
+				So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+			}
+			So(m.History("f1"), ShouldHaveLength, 2)
+
+			So(NewManager(WithHistoryDepth(0)).Run(t.Context()), ShouldNotBeNil)
+		})
+
+		Convey("Test duration stats", func() {
+			st := m.State()[0]
+			So(st.AvgDuration, ShouldEqual, time.Second)
+			So(st.P95Duration, ShouldEqual, time.Second)
+
+			h := NewMemoryHistory(50)
+			for i := range 20 {
+				So(h.Record(t.Context(), RunRecord{Job: "f1", Duration: time.Duration(i+1) * time.Second}), ShouldBeNil)
+			}
+			So(h.Record(t.Context(), RunRecord{Job: "f1", State: "skipped"}), ShouldBeNil)
+
+			avg, p95 := h.Stats("f1")
+			So(avg, ShouldEqual, 10500*time.Millisecond)
+			So(p95, ShouldEqual, 19*time.Second)
+		})
+	})
+}
@@ -0,0 +1,76 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrLeadershipLost is a context cause for runs canceled by CancelOnLoss.
+var ErrLeadershipLost = errors.New("leadership lost")
+
+// Leader reports if current instance is a leader, e.g. via etcd, Consul or Postgres advisory lock.
+type Leader interface {
+	IsLeader(ctx context.Context) bool
+}
+
+// LeaderFunc is an adapter to use ordinary function as Leader.
+type LeaderFunc func(ctx context.Context) bool
+
+// IsLeader implements Leader.
+func (f LeaderFunc) IsLeader(ctx context.Context) bool { return f(ctx) }
+
+// WithLeader runs scheduled jobs only on leader instance. Runs on followers are skipped with reason "not leader".
+// Losing leadership does not cancel running jobs, see CancelOnLoss. Manual runs are not affected.
+func WithLeader(l Leader) Option {
+	return func(cm *Manager) {
+		cm.leader = l
+	}
+}
+
+// CancelOnLoss checks leadership every interval during the run and cancels job context with ErrLeadershipLost cause
+// if leadership is lost. It is used with WithLeader.
+func CancelOnLoss(interval time.Duration) Option {
+	return func(cm *Manager) {
+		cm.leaderCheckInterval = interval
+	}
+}
+
+// newLeaderFunc wraps scheduled job fn with leadership check.
+func (cm *Manager) newLeaderFunc(idx int, fn Func) Func {
+	return func(ctx context.Context) error {
+		if !cm.leader.IsLeader(ctx) {
+			err := newSkipError(SkipReplica, "not leader")
+			cm.skipRun(idx, err)
+			return err
+		}
+
+		if cm.leaderCheckInterval <= 0 {
+			return fn(ctx)
+		}
+
+		ctx, cancel := context.WithCancelCause(ctx)
+		defer cancel(nil)
+		go cm.watchLeadership(ctx, cancel)
+
+		return fn(ctx)
+	}
+}
+
+// watchLeadership cancels ctx if leadership is lost.
+func (cm *Manager) watchLeadership(ctx context.Context, cancel context.CancelCauseFunc) {
+	t := time.NewTicker(cm.leaderCheckInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if !cm.leader.IsLeader(ctx) {
+				cancel(ErrLeadershipLost)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithLeader(t *testing.T) {
+	Convey("Test leader election", t, func() {
+		clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 30, 0, time.UTC)}
+
+		var isLeader atomic.Bool
+		leader := LeaderFunc(func(context.Context) bool { return isLeader.Load() })
+
+		Convey("Test followers skip runs", func() {
+			var runs int
+			m := NewManager(WithClock(clock), WithManualTicker(), WithLeader(leader))
+			m.AddFunc("f1", "* * * * *", func(context.Context) error {
+				runs++
+				return nil
+			})
+			So(m.Run(t.Context()), ShouldBeNil)
+
+			clock.Add(time.Minute)
+			So(errors.Is(m.Tick(t.Context(), clock.Now()), ErrSkipped), ShouldBeTrue)
+			So(m.State()[0].SkipReason, ShouldEqual, "not leader")
+			So(runs, ShouldEqual, 0)
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept", "text/html")
+			w := httptest.NewRecorder()
+			m.Handler(w, r)
+			So(w.Body.String(), ShouldContainSubstring, "Leadership: follower")
+
+			isLeader.Store(true)
+			clock.Add(time.Minute)
+			So(m.Tick(t.Context(), clock.Now()), ShouldBeNil)
+			So(runs, ShouldEqual, 1)
+		})
+
+		Convey("Test cancel on loss", func() {
+			isLeader.Store(true)
+			m := NewManager(WithClock(clock), WithManualTicker(), WithLeader(leader), CancelOnLoss(10*time.Millisecond))
+			m.AddFunc("f1", "* * * * *", func(ctx context.Context) error {
+				isLeader.Store(false)
+				<-ctx.Done()
+				return context.Cause(ctx)
+			})
+			So(m.Run(t.Context()), ShouldBeNil)
+
+			clock.Add(time.Minute)
+			So(errors.Is(m.Tick(t.Context(), clock.Now()), ErrLeadershipLost), ShouldBeTrue)
+		})
+	})
+}
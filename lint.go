@@ -0,0 +1,58 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// lintHorizon is a max expected interval until the next job run, see Lint.
+const lintHorizon = 365 * 24 * time.Hour
+
+// Warning is a non-fatal job configuration problem, see Manager.Lint.
+type Warning struct {
+	Job     string
+	Message string
+}
+
+// Lint checks job schedules and returns warnings for schedules which never fire (e.g. "0 0 30 2 *")
+// or fire later than in a year. Disabled jobs are not checked. Run logs warnings via WithManagerLogger
+// and UI shows them on the main page.
+func (cm *Manager) Lint() []Warning {
+	var ww []Warning
+	now := cm.clock.Now()
+	for _, j := range cm.jobs {
+		if !j.schedule.IsActive() {
+			continue
+		}
+
+		sch := j.sched
+		if sch == nil {
+			var err error
+			if sch, _, err = cm.parse(j.name, j.schedule); err != nil {
+				ww = append(ww, Warning{Job: j.name, Message: fmt.Sprintf("invalid schedule: %s", err)})
+				continue
+			}
+		}
+
+		switch next := sch.Next(now); {
+		case next.IsZero():
+			ww = append(ww, Warning{Job: j.name, Message: fmt.Sprintf("schedule %q never fires", j.schedule)})
+		case next.Sub(now) > lintHorizon:
+			ww = append(ww, Warning{Job: j.name, Message: fmt.Sprintf("schedule %q fires next time at %s", j.schedule, next.Format(time.DateOnly))})
+		}
+	}
+
+	return ww
+}
+
+// logLint logs Lint warnings via WithManagerLogger.
+func (cm *Manager) logLint(ctx context.Context) {
+	if cm.logger == nil {
+		return
+	}
+
+	for _, w := range cm.Lint() {
+		cm.logger.Print(ctx, "cron job schedule warning", "job", w.Job, "warning", w.Message)
+	}
+}
@@ -0,0 +1,40 @@
+package cron
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestManager_Lint(t *testing.T) {
+	Convey("Test schedules which never fire", t, func() {
+		clock := &testClock{now: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)}
+		lg := &testLogger{}
+		m := NewManager(WithClock(clock), WithManagerLogger(lg))
+		m.AddFunc("f1", "* * * * *", newCronFunc("f1"))
+		m.AddFunc("f2", "0 0 30 2 *", newCronFunc("f2"))
+		m.AddFunc("f3", "0 0 29 2 *", newCronFunc("f3"))
+		m.AddFunc("f4", "", newCronFunc("f4"))
+
+		ww := []Warning{
+			{Job: "f2", Message: `schedule "0 0 30 2 *" never fires`},
+			{Job: "f3", Message: `schedule "0 0 29 2 *" fires next time at 2028-02-29`},
+		}
+		So(m.Lint(), ShouldResemble, ww)
+
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+		msg, args := lg.last()
+		So(msg, ShouldEqual, "cron job schedule warning")
+		So(args, ShouldResemble, []any{"job", "f3", "warning", ww[1].Message})
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", "text/html")
+		w := httptest.NewRecorder()
+		m.Handler(w, r)
+		So(w.Body.String(), ShouldContainSubstring, "Warning: f2: schedule &#34;0 0 30 2 *&#34; never fires")
+	})
+}
@@ -0,0 +1,167 @@
+package cron
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	priorityKey        contextKey = "priority"
+	maintenanceWaitKey contextKey = "maintenanceWait"
+)
+
+// maintenanceLock is a RW lock for WithMaintenance: regular jobs share it, maintenance jobs hold it exclusively.
+// Waiting jobs acquire the lock in priority order (higher first) and then in arrival order.
+// If writerFirst is set, waiting exclusive lockers go before shared ones regardless of priority.
+type maintenanceLock struct {
+	mu          sync.Mutex
+	readers     int
+	writer      bool
+	writerFirst bool
+	queue       []*lockWaiter
+	seq         uint64
+}
+
+type lockWaiter struct {
+	write    bool
+	priority int
+	seq      uint64
+	ready    chan struct{}
+}
+
+// lock acquires the lock: exclusive if write is true, shared otherwise. It returns ctx error if ctx is done while waiting.
+func (l *maintenanceLock) lock(ctx context.Context, write bool, priority int) error {
+	l.mu.Lock()
+	if len(l.queue) == 0 && l.compatible(write) {
+		l.grant(write)
+		l.mu.Unlock()
+		return nil
+	}
+
+	l.seq++
+	w := &lockWaiter{write: write, priority: priority, seq: l.seq, ready: make(chan struct{})}
+	l.queue = append(l.queue, w)
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// lock was granted concurrently with ctx cancellation
+	i := slices.Index(l.queue, w)
+	if i < 0 {
+		return nil
+	}
+
+	l.queue = slices.Delete(l.queue, i, i+1)
+	l.dispatch()
+	return ctx.Err()
+}
+
+// unlock releases the lock acquired with the same write flag.
+func (l *maintenanceLock) unlock(write bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if write {
+		l.writer = false
+	} else {
+		l.readers--
+	}
+	l.dispatch()
+}
+
+// compatible checks if the lock can be granted right now.
+func (l *maintenanceLock) compatible(write bool) bool {
+	if write {
+		return !l.writer && l.readers == 0
+	}
+	return !l.writer
+}
+
+func (l *maintenanceLock) grant(write bool) {
+	if write {
+		l.writer = true
+	} else {
+		l.readers++
+	}
+}
+
+// dispatch grants the lock to waiters in priority order while they are compatible.
+func (l *maintenanceLock) dispatch() {
+	slices.SortStableFunc(l.queue, func(a, b *lockWaiter) int {
+		if l.writerFirst && a.write != b.write {
+			if a.write {
+				return -1
+			}
+			return 1
+		}
+		if c := cmp.Compare(b.priority, a.priority); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.seq, b.seq)
+	})
+
+	for len(l.queue) > 0 && l.compatible(l.queue[0].write) {
+		w := l.queue[0]
+		l.queue = l.queue[1:]
+		l.grant(w.write)
+		close(w.ready)
+	}
+}
+
+// Priority sets job priority for WithMaintenance lock: after maintenance job releases the lock,
+// waiting jobs with higher priority acquire it first. Default priority is 0.
+func Priority(n int) JobOption {
+	return JobContext(func(ctx context.Context) context.Context {
+		return NewPriorityContext(ctx, n)
+	})
+}
+
+// NewPriorityContext creates new context with job priority.
+func NewPriorityContext(ctx context.Context, priority int) context.Context {
+	return context.WithValue(ctx, priorityKey, priority)
+}
+
+// PriorityFromContext returns job priority from context.
+func PriorityFromContext(ctx context.Context) int {
+	if v, ok := ctx.Value(priorityKey).(int); ok {
+		return v
+	}
+
+	return 0
+}
+
+// NewMaintenanceWaitContext creates new context with time spent waiting for WithMaintenance lock.
+// Wait time of the lock is added to it, so it is available in all middleware after the lock is acquired,
+// including outer ones after next returns.
+func NewMaintenanceWaitContext(ctx context.Context, wait time.Duration) context.Context {
+	v := new(atomic.Int64)
+	v.Store(int64(wait))
+	return context.WithValue(ctx, maintenanceWaitKey, v)
+}
+
+// addLockWait adds time spent waiting for a lock to the run.
+func addLockWait(ctx context.Context, wait time.Duration) {
+	if v, ok := ctx.Value(maintenanceWaitKey).(*atomic.Int64); ok {
+		v.Add(int64(wait))
+	}
+}
+
+// MaintenanceWaitFromContext returns time spent waiting for WithMaintenance lock.
+func MaintenanceWaitFromContext(ctx context.Context) time.Duration {
+	if v, ok := ctx.Value(maintenanceWaitKey).(*atomic.Int64); ok {
+		return time.Duration(v.Load())
+	}
+
+	return 0
+}
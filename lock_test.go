@@ -0,0 +1,85 @@
+package cron
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMaintenanceLock(t *testing.T) {
+	Convey("Test maintenance lock", t, func() {
+		l := &maintenanceLock{}
+
+		Convey("Test priority order after exclusive lock", func() {
+			So(l.lock(t.Context(), true, 0), ShouldBeNil)
+
+			var (
+				mu    sync.Mutex
+				order []int
+				wg    sync.WaitGroup
+			)
+			for _, p := range []int{0, 10, 5} {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_ = l.lock(t.Context(), true, p)
+					mu.Lock()
+					order = append(order, p)
+					mu.Unlock()
+					l.unlock(true)
+				}()
+				time.Sleep(20 * time.Millisecond)
+			}
+
+			l.unlock(true)
+			wg.Wait()
+			So(order, ShouldResemble, []int{10, 5, 0})
+		})
+
+		Convey("Test writer priority", func() {
+			l.writerFirst = true
+			So(l.lock(t.Context(), false, 0), ShouldBeNil)
+
+			var (
+				mu    sync.Mutex
+				order []string
+				wg    sync.WaitGroup
+			)
+			for _, name := range []string{"writer", "reader"} {
+				wg.Add(1)
+				write := name == "writer"
+				go func() {
+					defer wg.Done()
+					_ = l.lock(t.Context(), write, map[bool]int{true: 0, false: 10}[write])
+					mu.Lock()
+					order = append(order, name)
+					mu.Unlock()
+					time.Sleep(10 * time.Millisecond)
+					l.unlock(write)
+				}()
+				time.Sleep(20 * time.Millisecond)
+			}
+
+			l.unlock(false)
+			wg.Wait()
+			So(order, ShouldResemble, []string{"writer", "reader"})
+		})
+
+		Convey("Test shared lock", func() {
+			So(l.lock(t.Context(), false, 0), ShouldBeNil)
+			So(l.lock(t.Context(), false, 0), ShouldBeNil)
+
+			ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+			defer cancel()
+			So(l.lock(ctx, true, 0), ShouldEqual, context.DeadlineExceeded)
+			So(l.queue, ShouldBeEmpty)
+
+			l.unlock(false)
+			l.unlock(false)
+			So(l.lock(t.Context(), true, 0), ShouldBeNil)
+		})
+	})
+}
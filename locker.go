@@ -0,0 +1,116 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const fencingTokenKey contextKey = "fencingToken"
+
+// LeaseHeldError is returned by Locker if lease is held by another owner.
+type LeaseHeldError struct {
+	Holder string
+}
+
+func (e LeaseHeldError) Error() string { return "lease is held by " + e.Holder }
+
+// Locker acquires named leases shared between replicas, e.g. in Redis or Postgres.
+type Locker interface {
+	// Lock acquires lease for name until ttl expires and returns monotonically increasing fencing token.
+	// It returns LeaseHeldError if lease is held by another owner.
+	Lock(ctx context.Context, name, owner string, ttl time.Duration) (token int64, err error)
+	// Unlock releases lease if it is held by owner.
+	Unlock(ctx context.Context, name, owner string) error
+}
+
+// WithManualRunLocker makes ManualRun acquire a lease for the job in l before executing, so the same job can't be
+// started manually on several replicas at once. Owner identifies current replica. Fencing token is available in job
+// via FencingTokenFromContext. If lease is held elsewhere, ManualRun returns ErrSkipped with the holder in reason.
+func WithManualRunLocker(l Locker, owner string, ttl time.Duration) Option {
+	return func(cm *Manager) {
+		cm.locker, cm.lockOwner, cm.lockTTL = l, owner, ttl
+	}
+}
+
+// lockManualRun acquires lease for manual run of the job and returns context with fencing token and unlock func.
+func (cm *Manager) lockManualRun(ctx context.Context, idx int) (context.Context, func(), error) {
+	name := "manual/" + cm.jobs[idx].name
+	if cm.name != "" {
+		name = cm.name + "/" + name
+	}
+
+	token, err := cm.locker.Lock(ctx, name, cm.lockOwner, cm.lockTTL)
+	var he LeaseHeldError
+	if errors.As(err, &he) {
+		err = newSkipError(SkipReplica, "manual run %s", he)
+		cm.skipRun(idx, err)
+		return ctx, nil, err
+	} else if err != nil {
+		return ctx, nil, fmt.Errorf("lock manual run: %w", err)
+	}
+
+	unlock := func() { _ = cm.locker.Unlock(context.WithoutCancel(ctx), name, cm.lockOwner) }
+	return NewFencingTokenContext(ctx, token), unlock, nil
+}
+
+// MemoryLocker is an in-process Locker. It is useful for tests and single instance setups.
+type MemoryLocker struct {
+	mu     sync.Mutex
+	token  int64
+	leases map[string]memoryLease
+	clock  Clock
+}
+
+type memoryLease struct {
+	owner     string
+	expiresAt time.Time
+}
+
+// NewMemoryLocker returns new in-process Locker.
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{leases: make(map[string]memoryLease), clock: realClock{}}
+}
+
+// Lock implements Locker.
+func (l *MemoryLocker) Lock(_ context.Context, name, owner string, ttl time.Duration) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	if ls, ok := l.leases[name]; ok && ls.owner != owner && now.Before(ls.expiresAt) {
+		return 0, LeaseHeldError{Holder: ls.owner}
+	}
+
+	l.token++
+	l.leases[name] = memoryLease{owner: owner, expiresAt: now.Add(ttl)}
+	return l.token, nil
+}
+
+// Unlock implements Locker.
+func (l *MemoryLocker) Unlock(_ context.Context, name, owner string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if ls, ok := l.leases[name]; ok && ls.owner == owner {
+		delete(l.leases, name)
+	}
+
+	return nil
+}
+
+// NewFencingTokenContext creates new context with fencing token of manual run lease.
+func NewFencingTokenContext(ctx context.Context, token int64) context.Context {
+	return context.WithValue(ctx, fencingTokenKey, token)
+}
+
+// FencingTokenFromContext returns fencing token of manual run lease or 0, see WithManualRunLocker.
+func FencingTokenFromContext(ctx context.Context) int64 {
+	if v, ok := ctx.Value(fencingTokenKey).(int64); ok {
+		return v
+	}
+
+	return 0
+}
@@ -0,0 +1,47 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithManualRunLocker(t *testing.T) {
+	Convey("Test manual run leases", t, func() {
+		locker := NewMemoryLocker()
+
+		var tokens []int64
+		started, release := make(chan struct{}, 1), make(chan struct{})
+		newManager := func(owner string) *Manager {
+			m := NewManager(WithManualRunLocker(locker, owner, time.Minute))
+			m.AddFunc("f1", "", func(ctx context.Context) error {
+				tokens = append(tokens, FencingTokenFromContext(ctx))
+				started <- struct{}{}
+				<-release
+				return nil
+			})
+			So(m.Run(t.Context()), ShouldBeNil)
+			return m
+		}
+
+		m1, m2 := newManager("r1"), newManager("r2")
+		errc := make(chan error)
+		go func() { errc <- m1.ManualRun(t.Context(), "f1") }()
+		<-started
+
+		err := m2.ManualRun(t.Context(), "f1")
+		So(errors.Is(err, ErrSkipped), ShouldBeTrue)
+		So(m2.State()[0].SkipReason, ShouldEqual, "manual run lease is held by r1")
+
+		close(release)
+		So(<-errc, ShouldBeNil)
+
+		// lease is released after run
+		So(m2.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		So(tokens, ShouldResemble, []int64{1, 2})
+		So(FencingTokenFromContext(t.Context()), ShouldEqual, 0)
+	})
+}
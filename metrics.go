@@ -0,0 +1,193 @@
+package cron
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metricEvaluated counts runs by state, see WithMetrics.
+var metricEvaluated = sync.OnceValue(func() *prometheus.CounterVec {
+	return mustRegister(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "app",
+		Subsystem: "cron",
+		Name:      "evaluated_total",
+		Help:      "Track all evaluations of cron.",
+	}, []string{"app", "cron", "state", "maintenance"}))
+})
+
+// metricActive shows running jobs, see WithMetrics.
+var metricActive = sync.OnceValue(func() *prometheus.GaugeVec {
+	return mustRegister(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "app",
+		Subsystem: "cron",
+		Name:      "active",
+		Help:      "Track current status of cron.",
+	}, []string{"app", "cron"}))
+})
+
+// metricDurations tracks run durations by state, see WithMetrics.
+var metricDurations = sync.OnceValue(func() *prometheus.SummaryVec {
+	return mustRegister(prometheus.NewSummaryVec(prometheus.SummaryOpts{
+		Namespace: "app",
+		Subsystem: "cron",
+		Name:      "evaluated_duration_seconds",
+		Help:      "Response time by cron.",
+	}, []string{"app", "cron", "state", "maintenance"}))
+})
+
+// metricLockWait tracks time spent waiting for WithMaintenance lock by lock type: read or write (with app label of WithMetrics).
+var metricLockWait = sync.OnceValue(func() *prometheus.SummaryVec {
+	return mustRegister(prometheus.NewSummaryVec(prometheus.SummaryOpts{
+		Namespace: "app",
+		Subsystem: "cron",
+		Name:      "lock_wait_seconds",
+		Help:      "Time spent waiting for maintenance lock.",
+	}, []string{"app", "cron", "lock"}))
+})
+
+// metricOverrun counts runs exceeded their max duration, see MaxDuration.
+var metricOverrun = sync.OnceValue(func() *prometheus.CounterVec {
+	return mustRegister(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "app",
+		Subsystem: "cron",
+		Name:      "overrun_total",
+		Help:      "Track runs exceeded max duration.",
+	}, []string{"app", "cron"}))
+})
+
+// metricQuotaExceeded counts runs skipped by WithMaxRunsPer.
+var metricQuotaExceeded = sync.OnceValue(func() *prometheus.CounterVec {
+	return mustRegister(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "app",
+		Subsystem: "cron",
+		Name:      "quota_exceeded_total",
+		Help:      "Track runs skipped due to exceeded runs quota.",
+	}, []string{"app", "cron"}))
+})
+
+// metricMissed counts runs missed during downtime, see WithStore.
+var metricMissed = sync.OnceValue(func() *prometheus.CounterVec {
+	return mustRegister(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "app",
+		Subsystem: "cron",
+		Name:      "missed_total",
+		Help:      "Track runs missed during downtime.",
+	}, []string{"app", "cron"}))
+})
+
+// metricStuck counts jobs marked as stuck by watchdog, see ExpectRuntime.
+var metricStuck = sync.OnceValue(func() *prometheus.CounterVec {
+	return mustRegister(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "app",
+		Subsystem: "cron",
+		Name:      "stuck_total",
+		Help:      "Track jobs running longer than expected.",
+	}, []string{"app", "cron"}))
+})
+
+// metricDraining shows if manager is drained, see Manager.Drain.
+var metricDraining = sync.OnceValue(func() *prometheus.GaugeVec {
+	return mustRegister(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "app",
+		Subsystem: "cron",
+		Name:      "draining",
+		Help:      "Shows if manager is drained and skips scheduled runs.",
+	}, []string{"manager"}))
+})
+
+// metricPoolSize shows workers of scheduled runs, see WithWorkerPool.
+var metricPoolSize = sync.OnceValue(func() *prometheus.GaugeVec {
+	return mustRegister(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "app",
+		Subsystem: "cron",
+		Name:      "pool_size",
+		Help:      "Shows number of worker pool workers.",
+	}, []string{"manager"}))
+})
+
+// metricPoolQueue shows scheduled runs waiting for a free worker, see WithWorkerPool.
+var metricPoolQueue = sync.OnceValue(func() *prometheus.GaugeVec {
+	return mustRegister(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "app",
+		Subsystem: "cron",
+		Name:      "pool_queue",
+		Help:      "Shows scheduled runs queued to worker pool.",
+	}, []string{"manager"}))
+})
+
+// metricAutoDisabled counts jobs disabled after failures, see DisableAfterFailures.
+var metricAutoDisabled = sync.OnceValue(func() *prometheus.CounterVec {
+	return mustRegister(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "app",
+		Subsystem: "cron",
+		Name:      "auto_disabled_total",
+		Help:      "Track jobs disabled after consecutive failures.",
+	}, []string{"app", "cron"}))
+})
+
+// metricBreakerSkipped counts runs skipped by open circuit breaker, see WithCircuitBreaker.
+var metricBreakerSkipped = sync.OnceValue(func() *prometheus.CounterVec {
+	return mustRegister(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "app",
+		Subsystem: "cron",
+		Name:      "breaker_skipped_total",
+		Help:      "Track runs skipped by open circuit breaker.",
+	}, []string{"app", "cron"}))
+})
+
+// metricResourceInUse shows used slots of a resource, see WithResource.
+var metricResourceInUse = sync.OnceValue(func() *prometheus.GaugeVec {
+	return mustRegister(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "app",
+		Subsystem: "cron",
+		Name:      "resource_in_use",
+		Help:      "Shows used slots of a resource.",
+	}, []string{"app", "resource"}))
+})
+
+// metricResourceCapacity shows slots of a resource, see WithResource.
+var metricResourceCapacity = sync.OnceValue(func() *prometheus.GaugeVec {
+	return mustRegister(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "app",
+		Subsystem: "cron",
+		Name:      "resource_capacity",
+		Help:      "Shows slots of a resource.",
+	}, []string{"app", "resource"}))
+})
+
+// metricDependencySkipped counts runs skipped because a dependency is down, see WithDependency.
+var metricDependencySkipped = sync.OnceValue(func() *prometheus.CounterVec {
+	return mustRegister(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "app",
+		Subsystem: "cron",
+		Name:      "dependency_skipped_total",
+		Help:      "Track runs skipped because a dependency is down.",
+	}, []string{"app", "cron", "dependency"}))
+})
+
+// metricBudgetRemaining shows remaining execution budget, see WithBudget.
+var metricBudgetRemaining = sync.OnceValue(func() *prometheus.GaugeVec {
+	return mustRegister(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "app",
+		Subsystem: "cron",
+		Name:      "budget_remaining_seconds",
+		Help:      "Shows remaining execution budget in the window.",
+	}, []string{"app", "cron"}))
+})
+
+// mustRegister registers collector in default prometheus registry or returns already registered one.
+func mustRegister[T prometheus.Collector](c T) T {
+	if err := prometheus.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+
+	return c
+}
@@ -5,22 +5,30 @@ import (
 	"errors"
 	"fmt"
 	"runtime"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/getsentry/sentry-go"
-	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+	"golang.org/x/time/rate"
 )
 
 const (
-	isDevelCtx contextKey = "isDevelKey"
+	isDevelCtx       contextKey = "isDevelKey"
+	remainingRunsCtx contextKey = "remainingRuns"
+	noSkipCtx        contextKey = "noSkip"
+	metricsAppCtx    contextKey = "metricsApp"
 )
 
 // WithLogger logs via Printf function (e.g. log.Printf) all runs.
 func WithLogger(pf LogPrintf, managerName string) MiddlewareFunc {
 	return func(next Func) Func {
 		return func(ctx context.Context) error {
-			start := time.Now()
+			clock := clockFromContext(ctx)
+			start := clock.Now()
 			err := next(ctx)
 
 			// set error msg for %q
@@ -31,14 +39,13 @@ func WithLogger(pf LogPrintf, managerName string) MiddlewareFunc {
 				errMsg = err.Error()
 			}
 
-			pf("cron job %s job=%s duration=%v err=%q manager=%s maintenance=%v",
-				state,
-				NameFromContext(ctx),
-				time.Since(start),
-				errMsg,
-				managerName,
-				MaintenanceFromContext(ctx),
-			)
+			format := "cron job %s job=%s duration=%v err=%q manager=%s maintenance=%v"
+			args := []any{state, NameFromContext(ctx), clock.Since(start), errMsg, managerName, MaintenanceFromContext(ctx)}
+			if principal := PrincipalFromContext(ctx); principal != "" {
+				format, args = format+" principal=%s", append(args, principal)
+			}
+
+			pf(format, args...)
 			return err
 		}
 	}
@@ -50,21 +57,52 @@ type Logger interface {
 	Error(ctx context.Context, msg string, args ...any)
 }
 
-// WithSLog logs all runs via slog (see Logger interface).
+// debugLogger is optional Logger with debug level, e.g. for WithIgnoreErrors.
+type debugLogger interface {
+	Debug(ctx context.Context, msg string, args ...any)
+}
+
+// WithSLog logs all runs via slog (see Logger interface). Duration excludes time spent waiting for maintenance lock,
+// it is logged as lockWait.
+// Manager name is taken from context, see WithName manager option.
 func WithSLog(lg Logger) MiddlewareFunc {
 	return func(next Func) Func {
 		return func(ctx context.Context) error {
-			start := time.Now()
+			clock := clockFromContext(ctx)
+			start := clock.Now()
 			err := next(ctx)
 
-			d, name := time.Since(start), NameFromContext(ctx)
+			wait := MaintenanceWaitFromContext(ctx)
+			args := []any{
+				"job", NameFromContext(ctx),
+				"duration", clock.Since(start) - wait,
+				"maintenance", MaintenanceFromContext(ctx),
+			}
+			if wait > 0 {
+				args = append(args, "lockWait", wait)
+			}
+			if manager := ManagerNameFromContext(ctx); manager != "" {
+				args = append(args, "manager", manager)
+			}
+			if principal := PrincipalFromContext(ctx); principal != "" {
+				args = append(args, "principal", principal)
+			}
+
+			var se SkipError
 			switch {
+			case errors.As(err, &se):
+				lg.Print(ctx, "cron job skipped", append(args, "kind", se.Kind, "reason", se.Reason)...)
 			case errors.Is(err, ErrSkipped):
-				lg.Print(ctx, "cron job skipped", "job", name, "duration", d)
+				lg.Print(ctx, "cron job skipped", args...)
 			case err != nil:
-				lg.Error(ctx, "cron job failed", "job", name, "duration", d, "err", err)
+				args = append(args, "err", err)
+				var pe *PanicError
+				if errors.As(err, &pe) {
+					args = append(args, "stack", string(pe.Stack))
+				}
+				lg.Error(ctx, "cron job failed", args...)
 			default:
-				lg.Print(ctx, "cron job finished", "job", name, "duration", d)
+				lg.Print(ctx, "cron job finished", args...)
 			}
 
 			return err
@@ -72,28 +110,61 @@ func WithSLog(lg Logger) MiddlewareFunc {
 	}
 }
 
+// SentryOptions are options for WithSentryOptions.
+type SentryOptions struct {
+	// Level returns event level for error, default is sentry.LevelError.
+	Level func(error) sentry.Level
+
+	// Throttle captures identical errors (same job and message) at most once per interval. Number of suppressed
+	// errors is sent as "suppressed" extra with the next captured event. Panics are always captured.
+	Throttle time.Duration
+
+	// MaxPerHour limits captured errors per job in the last hour, 0 is unlimited. Panics are always captured.
+	MaxPerHour int
+}
+
 // WithSentry sends all errors to sentry. It's also handles panics.
+// Events are tagged with cron, maintenance, devel, manager (if set) and transient (for Transient and Permanent errors)
+// and have cron context with run duration.
 func WithSentry() MiddlewareFunc {
+	return WithSentryOptions(SentryOptions{})
+}
+
+// WithSentryOptions is WithSentry with options, e.g. to report expected errors with warning level.
+func WithSentryOptions(opts SentryOptions) MiddlewareFunc {
+	throttle := newSentryThrottle(opts.Throttle, opts.MaxPerHour)
+
 	return func(next Func) Func {
 		return func(ctx context.Context) (err error) {
+			clock := clockFromContext(ctx)
+			start := clock.Now()
 			defer func() {
-				var rec any
-				if rec = recover(); rec != nil {
-					switch e := rec.(type) {
-					case error:
-						err = e
-					default:
-						err = fmt.Errorf("%v", e)
-					}
-				}
-
-				if err != nil {
-					sentryHub := sentry.CurrentHub().Clone()
-					sentryHub.WithScope(func(scope *sentry.Scope) {
-						scope.SetTag("cron", NameFromContext(ctx))
-					})
-					sentryHub.CaptureException(err)
+				if rec := recover(); rec != nil {
+					err = newPanicError(rec)
+				}
+
+				if err == nil {
+					return
+				}
+
+				var pe *PanicError
+				capture, suppressed := throttle.allow(NameFromContext(ctx), err.Error(), clock.Now())
+				if !capture && !errors.As(err, &pe) {
+					return
+				}
+
+				sentryHub := sentry.CurrentHub().Clone()
+				setSentryScope(ctx, sentryHub.Scope(), clock.Since(start))
+				if opts.Level != nil {
+					sentryHub.Scope().SetLevel(opts.Level(err))
 				}
+				if suppressed > 0 {
+					sentryHub.Scope().SetExtra("suppressed", suppressed)
+				}
+				if class := errorClass(err); class != "" {
+					sentryHub.Scope().SetTag("transient", strconv.FormatBool(class == errorTransient))
+				}
+				sentryHub.CaptureException(err)
 			}()
 
 			return next(ctx)
@@ -101,16 +172,174 @@ func WithSentry() MiddlewareFunc {
 	}
 }
 
-// WithRecover use recover() func. Do not use with WithSentry middleware due to recover() call.
+// sentryThrottle limits identical sentry events, see SentryOptions.Throttle and SentryOptions.MaxPerHour.
+type sentryThrottle struct {
+	mu          sync.Mutex
+	minInterval time.Duration
+	maxPerHour  int
+	errs        map[string]*throttledError // by job and error message
+	captured    map[string][]time.Time     // by job, during the last hour
+	pruned      time.Time
+}
+
+type throttledError struct {
+	last       time.Time
+	suppressed int
+}
+
+func newSentryThrottle(minInterval time.Duration, maxPerHour int) *sentryThrottle {
+	return &sentryThrottle{
+		minInterval: minInterval,
+		maxPerHour:  maxPerHour,
+		errs:        make(map[string]*throttledError),
+		captured:    make(map[string][]time.Time),
+	}
+}
+
+// allow checks if error should be captured and returns number of suppressed identical errors since the last capture.
+func (t *sentryThrottle) allow(job, msg string, now time.Time) (bool, int) {
+	if t.minInterval <= 0 && t.maxPerHour <= 0 {
+		return true, 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.prune(now)
+
+	key := job + "\x00" + msg
+	te, ok := t.errs[key]
+	if !ok {
+		te = &throttledError{}
+		t.errs[key] = te
+	}
+
+	// forget captures older than an hour
+	captured := t.captured[job]
+	i := 0
+	for i < len(captured) && now.Sub(captured[i]) >= time.Hour {
+		i++
+	}
+	captured = captured[i:]
+	t.captured[job] = captured
+
+	if now.Sub(te.last) < t.minInterval || t.maxPerHour > 0 && len(captured) >= t.maxPerHour {
+		te.suppressed++
+		return false, 0
+	}
+
+	suppressed := te.suppressed
+	te.last, te.suppressed = now, 0
+	t.captured[job] = append(captured, now)
+
+	return true, suppressed
+}
+
+// prune forgets errors not captured during the last hour or minInterval, so errors with ids or counters in
+// messages don't grow the map. It runs at most once per hour.
+func (t *sentryThrottle) prune(now time.Time) {
+	ttl := max(t.minInterval, time.Hour)
+	if now.Sub(t.pruned) < ttl {
+		return
+	}
+
+	t.pruned = now
+	for key, te := range t.errs {
+		if now.Sub(te.last) >= ttl {
+			delete(t.errs, key)
+		}
+	}
+}
+
+// setSentryScope sets job tags and context to sentry scope.
+func setSentryScope(ctx context.Context, scope *sentry.Scope, duration time.Duration) {
+	scope.SetTag("cron", NameFromContext(ctx))
+	scope.SetTag("maintenance", strconv.FormatBool(MaintenanceFromContext(ctx)))
+	scope.SetTag("devel", strconv.FormatBool(IsDevelFromContext(ctx)))
+
+	c := sentry.Context{"duration": duration.String()}
+	if name := ManagerNameFromContext(ctx); name != "" {
+		scope.SetTag("manager", name)
+		c["manager"] = name
+	}
+	scope.SetContext("cron", c)
+}
+
+// PanicError is an error from recovered panic with its stack trace.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+// newPanicError returns PanicError with current goroutine stack.
+func newPanicError(rec any) *PanicError {
+	stack := make([]byte, 64<<10)
+	stack = stack[:runtime.Stack(stack, false)]
+	return &PanicError{Value: rec, Stack: stack}
+}
+
+func (e *PanicError) Error() string { return fmt.Sprintf("panic: %v", e.Value) }
+
+// Unwrap returns panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// WithIgnoreErrors converts errors matched by any of matchers to nil, e.g. "nothing to do" sentinel errors.
+// Ignored errors are logged at debug level via WithManagerLogger if the logger has Debug method with Logger arguments.
+// Add it after WithMetrics and WithSentry, so they observe successful run instead of the error.
+func WithIgnoreErrors(matchers ...func(error) bool) MiddlewareFunc {
+	return ignoreErrors(false, matchers)
+}
+
+// WithSkipErrors is WithIgnoreErrors which converts matched errors to ErrSkipped with error text as skip reason.
+func WithSkipErrors(matchers ...func(error) bool) MiddlewareFunc {
+	return ignoreErrors(true, matchers)
+}
+
+func ignoreErrors(skip bool, matchers []func(error) bool) MiddlewareFunc {
+	return func(next Func) Func {
+		return func(ctx context.Context) error {
+			// skips are kept even if their cause matches, e.g. from inner WithSkipErrors
+			err := next(ctx)
+			if err == nil || errors.Is(err, ErrSkipped) || !slices.ContainsFunc(matchers, func(m func(error) bool) bool { return m(err) }) {
+				return err
+			}
+
+			if lg, ok := ctx.Value(loggerKey).(debugLogger); ok {
+				lg.Debug(ctx, "cron job error ignored", "job", NameFromContext(ctx), "err", err)
+			}
+			if skip {
+				return wrapSkipError(err, SkipIgnored, "%s", err)
+			}
+
+			return nil
+		}
+	}
+}
+
+// ErrorIs returns WithIgnoreErrors matcher based on errors.Is.
+func ErrorIs(target error) func(error) bool {
+	return func(err error) bool { return errors.Is(err, target) }
+}
+
+// ErrorContains returns WithIgnoreErrors matcher which checks error text for substr.
+func ErrorContains(substr string) func(error) bool {
+	return func(err error) bool { return strings.Contains(err.Error(), substr) }
+}
+
+// WithRecover use recover() func. Do not use with WithSentry middleware due to recover() call:
+// Run logs a warning via WithManagerLogger if both are used.
 func WithRecover() MiddlewareFunc {
 	return func(next Func) Func {
 		return func(ctx context.Context) (err error) {
 			// recover
 			defer func() {
 				if rec := recover(); rec != nil {
-					stack := make([]byte, 64<<10)
-					stack = stack[:runtime.Stack(stack, false)]
-					err = fmt.Errorf("panic: %v: %s", rec, stack)
+					err = newPanicError(rec)
 				}
 			}()
 
@@ -129,6 +358,16 @@ func WithDevel(isDevel bool) MiddlewareFunc {
 	}
 }
 
+// WithContextValues derives job context with fn, e.g. to add tenant, logger or DB handle to every run.
+// Job name, maintenance and other values set by Manager are available in fn.
+func WithContextValues(fn func(ctx context.Context) context.Context) MiddlewareFunc {
+	return func(next Func) Func {
+		return func(ctx context.Context) error {
+			return next(fn(ctx))
+		}
+	}
+}
+
 // NewIsDevelContext creates new context with isDevel flag.
 func NewIsDevelContext(ctx context.Context, isDevel bool) context.Context {
 	return context.WithValue(ctx, isDevelCtx, isDevel)
@@ -142,7 +381,32 @@ func IsDevelFromContext(ctx context.Context) bool {
 	return false
 }
 
-// WithSkipActive skips funcs if they are already running.
+// OnlyInDevel runs job only in development environment, see WithDevel.
+func OnlyInDevel() JobOption {
+	return envGate("devel", true)
+}
+
+// SkipInDevel runs job only in production environment, see WithDevel.
+func SkipInDevel() JobOption {
+	return envGate("production", false)
+}
+
+// envGate skips job runs if isDevel flag from context is not equal to isDevel.
+func envGate(env string, isDevel bool) JobOption {
+	return func(j *job) {
+		j.env = env
+		j.middleware = append(j.middleware, func(next Func) Func {
+			return func(ctx context.Context) error {
+				if IsDevelFromContext(ctx) != isDevel {
+					return newSkipError(SkipDisabled, "environment: %s only", env)
+				}
+				return next(ctx)
+			}
+		})
+	}
+}
+
+// WithSkipActive skips funcs if they are already running. Jobs with NoSkipActive option are not skipped.
 func WithSkipActive() MiddlewareFunc {
 	active := map[string]struct{}{}
 	mu := sync.Mutex{}
@@ -150,12 +414,15 @@ func WithSkipActive() MiddlewareFunc {
 	return func(next Func) Func {
 		return func(ctx context.Context) error {
 			name := NameFromContext(ctx)
+			if NoSkipFromContext(ctx) {
+				return next(ctx)
+			}
 
 			// check for running function
 			mu.Lock()
 			if _, ok := active[name]; ok {
 				mu.Unlock()
-				return ErrSkipped
+				return newSkipError(SkipActive, "already running")
 			}
 
 			// set active name
@@ -173,78 +440,480 @@ func WithSkipActive() MiddlewareFunc {
 	}
 }
 
+// WithMinInterval skips runs if the job succeeded less than d ago.
+// It uses last success from Manager state, so manual runs are counted too.
+func WithMinInterval(d time.Duration) MiddlewareFunc {
+	return func(next Func) Func {
+		return func(ctx context.Context) error {
+			last := LastSuccessFromContext(ctx)
+			if since := clockFromContext(ctx).Since(last); !last.IsZero() && since < d {
+				return newSkipError(SkipLimit, "succeeded %v ago, eligible in %v", since.Round(time.Second), (d - since).Round(time.Second))
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// WithResultCache skips runs if the job succeeded less than ttl ago with the same cache key, e.g. hash of job config.
+// Changed key forces a run inside ttl, failed run invalidates the cache.
+func WithResultCache(ttl time.Duration, key func(ctx context.Context) string) MiddlewareFunc {
+	type result struct {
+		key string
+		at  time.Time
+	}
+	cache := map[string]result{}
+	mu := sync.Mutex{}
+
+	return func(next Func) Func {
+		return func(ctx context.Context) error {
+			name, k, clock := NameFromContext(ctx), key(ctx), clockFromContext(ctx)
+
+			mu.Lock()
+			r, ok := cache[name]
+			mu.Unlock()
+			if age := clock.Since(r.at); ok && r.key == k && age < ttl {
+				return newSkipError(SkipLimit, "cached success %v ago, eligible in %v", age.Round(time.Second), (ttl - age).Round(time.Second))
+			}
+
+			err := next(ctx)
+
+			mu.Lock()
+			if err == nil {
+				cache[name] = result{key: k, at: clock.Now()}
+			} else if !errors.Is(err, ErrSkipped) {
+				delete(cache, name)
+			}
+			mu.Unlock()
+
+			return err
+		}
+	}
+}
+
+// WithRateLimit skips runs started less than d after the previous start of the same job, e.g. repeated manual runs
+// while the schedule is also firing. Unlike WithMinInterval it counts starts, not successes.
+func WithRateLimit(d time.Duration) MiddlewareFunc {
+	starts := map[string]time.Time{}
+	mu := sync.Mutex{}
+
+	return func(next Func) Func {
+		return func(ctx context.Context) error {
+			name, now := NameFromContext(ctx), clockFromContext(ctx).Now()
+
+			mu.Lock()
+			last, ok := starts[name]
+			if since := now.Sub(last); ok && since < d {
+				mu.Unlock()
+				return newSkipError(SkipLimit, "rate limited: started %v ago, eligible in %v", since.Round(time.Second), (d - since).Round(time.Second))
+			}
+			starts[name] = now
+			mu.Unlock()
+
+			return next(ctx)
+		}
+	}
+}
+
+// WithTokenBucket waits for a token of rate limiter with rate r and burst b before each run, e.g. to stay under
+// external API limits. Job is in waiting state while it waits for a token.
+// The limiter is created per WithTokenBucket call: pass the same middleware to several jobs (or to Manager.Use)
+// to share the limit between them, or call WithTokenBucket for each job to limit jobs separately:
+//
+//	apiLimit := cron.WithTokenBucket(10, 1)
+//	m.AddFunc("f1", "* * * * *", f1, cron.JobMiddleware(apiLimit))
+//	m.AddFunc("f2", "* * * * *", f2, cron.JobMiddleware(apiLimit))
+func WithTokenBucket(r rate.Limit, b int) MiddlewareFunc {
+	l := rate.NewLimiter(r, b)
+
+	return func(next Func) Func {
+		return func(ctx context.Context) error {
+			if !l.Allow() {
+				setState(ctx, stateWaiting)
+				if err := l.Wait(ctx); err != nil {
+					return err
+				}
+				setState(ctx, stateRunning)
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// WithDelay starts the job d after the trigger, e.g. to let an upstream job on another system finish.
+// Job is in waiting state during the delay, delay is excluded from job duration.
+func WithDelay(d time.Duration) MiddlewareFunc {
+	return func(next Func) Func {
+		return func(ctx context.Context) error {
+			setState(ctx, stateWaiting)
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-clockAfter(clockFromContext(ctx), d):
+			}
+
+			setState(ctx, stateRunning)
+			return next(ctx)
+		}
+	}
+}
+
+// WithDeadlineFunc cancels job context at absolute deadline returned by fn for each run, e.g. to finish before
+// the maintenance window. Zero deadline means no deadline. Runs with passed deadline are not started.
+func WithDeadlineFunc(fn func(ctx context.Context) time.Time) MiddlewareFunc {
+	return func(next Func) Func {
+		return func(ctx context.Context) error {
+			deadline := fn(ctx)
+			if deadline.IsZero() {
+				return next(ctx)
+			}
+
+			ctx, cancel := context.WithDeadline(ctx, deadline)
+			defer cancel()
+
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// WithMaxRunsPer allows at most n runs of each job per sliding window.
+// Runs over the quota are skipped and counted in app_cron_quota_exceeded_total metric (with app label
+// if WithMetrics is added before). Remaining quota is available in job via RemainingRunsFromContext.
+// Every run is skipped if n < 1 or window <= 0.
+func WithMaxRunsPer(window time.Duration, n int) MiddlewareFunc {
+	runs := map[string][]time.Time{}
+	mu := sync.Mutex{}
+
+	return func(next Func) Func {
+		return func(ctx context.Context) error {
+			name, now := NameFromContext(ctx), clockFromContext(ctx).Now()
+			if n < 1 || window <= 0 {
+				app, _ := metricsAppFromContext(ctx)
+				metricQuotaExceeded().WithLabelValues(app, name).Inc()
+				return newSkipError(SkipLimit, "invalid quota: %d runs per %v", n, window)
+			}
+
+			mu.Lock()
+			// drop runs outside the window
+			rr := runs[name]
+			for len(rr) > 0 && now.Sub(rr[0]) >= window {
+				rr = rr[1:]
+			}
+
+			if len(rr) >= n {
+				runs[name] = rr
+				mu.Unlock()
+
+				app, _ := metricsAppFromContext(ctx)
+				metricQuotaExceeded().WithLabelValues(app, name).Inc()
+				return newSkipError(SkipLimit, "quota exceeded: %d runs per %v, next run allowed at %s", n, window, rr[0].Add(window).Format(time.DateTime))
+			}
+
+			rr = append(rr, now)
+			runs[name] = rr
+			mu.Unlock()
+
+			return next(NewRemainingRunsContext(ctx, n-len(rr)))
+		}
+	}
+}
+
+// NewRemainingRunsContext creates new context with remaining runs quota.
+func NewRemainingRunsContext(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, remainingRunsCtx, n)
+}
+
+// RemainingRunsFromContext returns remaining runs quota from WithMaxRunsPer or -1 if there is no quota.
+func RemainingRunsFromContext(ctx context.Context) int {
+	if n, ok := ctx.Value(remainingRunsCtx).(int); ok {
+		return n
+	}
+	return -1
+}
+
+// BackoffError asks WithBackoff to skip job runs until Until or for RetryAfter duration, e.g. on rate limits.
+type BackoffError struct {
+	Until      time.Time
+	RetryAfter time.Duration // used if Until is zero
+	Err        error
+}
+
+func (e BackoffError) Error() string {
+	if e.Err == nil {
+		return "backoff requested"
+	}
+	return "backoff requested: " + e.Err.Error()
+}
+
+func (e BackoffError) Unwrap() error { return e.Err }
+
+// WithBackoff skips job runs after job returned BackoffError until its deadline passes.
+func WithBackoff() MiddlewareFunc {
+	until := map[string]time.Time{}
+	mu := sync.Mutex{}
+
+	return func(next Func) Func {
+		return func(ctx context.Context) error {
+			name, now := NameFromContext(ctx), clockFromContext(ctx).Now()
+
+			mu.Lock()
+			t := until[name]
+			mu.Unlock()
+
+			if now.Before(t) {
+				return newSkipError(SkipGuard, "backoff until %s", t.Format(time.DateTime))
+			}
+
+			err := next(ctx)
+
+			var be BackoffError
+			if errors.As(err, &be) {
+				t = be.Until
+				if t.IsZero() {
+					t = clockFromContext(ctx).Now().Add(be.RetryAfter)
+				}
+
+				mu.Lock()
+				until[name] = t
+				mu.Unlock()
+			}
+
+			return err
+		}
+	}
+}
+
+// WithSingleFlight shares one execution between concurrent runs of the same job (keyed by job name):
+// e.g. scheduled and manual runs fired at the same time. All callers get the same result.
+// Unlike WithSkipActive, concurrent callers are not skipped, but wait for the running execution.
+func WithSingleFlight() MiddlewareFunc {
+	var g singleflight.Group
+
+	return func(next Func) Func {
+		return func(ctx context.Context) error {
+			_, err, _ := g.Do(NameFromContext(ctx), func() (any, error) {
+				return nil, next(ctx)
+			})
+			return err
+		}
+	}
+}
+
+// NoSkipActive exempts job from WithSkipActive middleware.
+func NoSkipActive() JobOption {
+	return JobContext(func(ctx context.Context) context.Context {
+		return NewNoSkipContext(ctx, true)
+	})
+}
+
+// NewNoSkipContext creates new context with noSkip flag for WithSkipActive.
+func NewNoSkipContext(ctx context.Context, noSkip bool) context.Context {
+	return context.WithValue(ctx, noSkipCtx, noSkip)
+}
+
+// NoSkipFromContext returns noSkip flag from context.
+func NoSkipFromContext(ctx context.Context) bool {
+	if noSkip, ok := ctx.Value(noSkipCtx).(bool); ok {
+		return noSkip
+	}
+	return false
+}
+
+// Unless bypasses middleware m for runs where exempt returns true.
+// Combine it with JobContext to exempt jobs from any global middleware:
+//
+//	m.Use(cron.Unless(isNoLock, cron.WithMaintenance(log.Printf)))
+//	m.AddFunc("f1", "* * * * *", fn, cron.JobContext(newNoLockContext))
+func Unless(exempt func(ctx context.Context) bool, m MiddlewareFunc) MiddlewareFunc {
+	return func(next Func) Func {
+		wrapped := m(next)
+		return func(ctx context.Context) error {
+			if exempt(ctx) {
+				return next(ctx)
+			}
+			return wrapped(ctx)
+		}
+	}
+}
+
 // WithMaintenance puts cron jobs in line, got exclusive lock for maintenance job.
+// Job is in waiting state while it waits for the lock. Waiting jobs acquire the lock by Priority.
 func WithMaintenance(p LogPrintf) MiddlewareFunc {
-	mutex := sync.RWMutex{}
+	return WithMaintenanceOptions(MaintenanceOptions{Logger: p})
+}
+
+// MaintenanceOptions are options for WithMaintenanceOptions.
+type MaintenanceOptions struct {
+	// Logger logs maintenance lock acquisition and release.
+	Logger LogPrintf
+
+	// OnRelease is called when a job releases the lock with time spent waiting for the lock and holding it.
+	OnRelease func(ctx context.Context, wait, held time.Duration)
+
+	// WriterPriority lets waiting maintenance jobs acquire the lock before waiting regular jobs regardless of Priority.
+	WriterPriority bool
+
+	// AcquireTimeout limits time regular jobs wait for the lock, after it the run is skipped. Zero means no limit.
+	AcquireTimeout time.Duration
+}
+
+// WithMaintenanceTimeout is WithMaintenance, but regular jobs give up waiting for the lock after acquire and are skipped,
+// so a stuck maintenance job doesn't block all jobs forever.
+func WithMaintenanceTimeout(p LogPrintf, acquire time.Duration) MiddlewareFunc {
+	return WithMaintenanceOptions(MaintenanceOptions{Logger: p, AcquireTimeout: acquire})
+}
+
+// WithMaintenanceOptions is WithMaintenance with options, e.g. to measure time jobs wait for each other.
+// Wait time is available in job context via MaintenanceWaitFromContext and in app_cron_lock_wait_seconds metric.
+func WithMaintenanceOptions(opts MaintenanceOptions) MiddlewareFunc {
+	lock := &maintenanceLock{writerFirst: opts.WriterPriority}
 	pf := func(format string, v ...interface{}) {
-		if p != nil {
-			p(format, v...)
+		if opts.Logger != nil {
+			opts.Logger(format, v...)
 		}
 	}
 
 	return func(next Func) Func {
 		return func(ctx context.Context) error {
 			name, isMaintenance := NameFromContext(ctx), MaintenanceFromContext(ctx)
+			clock := clockFromContext(ctx)
+			setState(ctx, stateWaiting)
 			if isMaintenance {
 				pf("cron getting maintenance lock=%v", name)
-				mutex.Lock()
-				pf("cron got maintenance lock=%v", name)
-			} else {
-				mutex.RLock()
 			}
 
-			err := next(ctx)
+			start := clock.Now()
+			if err := acquireLock(ctx, lock, isMaintenance, opts.AcquireTimeout); err != nil {
+				if errors.Is(err, ErrSkipped) {
+					pf("cron skipped job=%v, maintenance lock wait timeout=%v", name, opts.AcquireTimeout)
+				}
+				return err
+			}
+			acquired := clock.Now()
+			wait := acquired.Sub(start)
+			addLockWait(ctx, wait)
+			app, _ := metricsAppFromContext(ctx)
+			metricLockWait().WithLabelValues(app, name, lockType(isMaintenance)).Observe(wait.Seconds())
 			if isMaintenance {
-				mutex.Unlock()
-			} else {
-				mutex.RUnlock()
+				pf("cron got maintenance lock=%v wait=%v", name, wait)
 			}
+			setState(ctx, stateRunning)
 
-			return err
+			defer func() {
+				lock.unlock(isMaintenance)
+				held := clock.Now().Sub(acquired)
+				if isMaintenance {
+					pf("cron released maintenance lock=%v held=%v", name, held)
+				}
+				if opts.OnRelease != nil {
+					opts.OnRelease(ctx, wait, held)
+				}
+			}()
+
+			return next(ctx)
 		}
 	}
 }
 
-// WithMetrics tracks total/active/duration metrics for runs.
-func WithMetrics(app string) MiddlewareFunc {
-	statEvaluated := prometheus.NewCounterVec(prometheus.CounterOpts{
-		Namespace: "app",
-		Subsystem: "cron",
-		Name:      "evaluated_total",
-		Help:      "Track all evaluations of cron.",
-	}, []string{"app", "cron", "state"})
+// lockType returns lock label for metrics.
+func lockType(write bool) string {
+	if write {
+		return "write"
+	}
+	return "read"
+}
 
-	statActive := prometheus.NewGaugeVec(prometheus.GaugeOpts{
-		Namespace: "app",
-		Subsystem: "cron",
-		Name:      "active",
-		Help:      "Track current status of cron.",
-	}, []string{"app", "cron"})
+// acquireLock acquires maintenance lock. Regular job is skipped if it waits longer than timeout.
+func acquireLock(ctx context.Context, lock *maintenanceLock, write bool, timeout time.Duration) error {
+	if write || timeout <= 0 {
+		return lock.lock(ctx, write, PriorityFromContext(ctx))
+	}
+
+	lctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	statDurations := prometheus.NewSummaryVec(prometheus.SummaryOpts{
-		Namespace: "app",
-		Subsystem: "cron",
-		Name:      "evaluated_duration_seconds",
-		Help:      "Response time by cron.",
-	}, []string{"app", "cron", "state"})
+	err := lock.lock(lctx, write, PriorityFromContext(ctx))
+	if err != nil && ctx.Err() == nil {
+		return newSkipError(SkipMaintenance, "maintenance lock wait timeout %v", timeout)
+	}
 
-	prometheus.MustRegister(statEvaluated, statActive, statDurations)
+	return err
+}
+
+// WithMetrics tracks total/active/duration metrics for runs. Collectors are shared between managers.
+// Duration excludes time spent waiting in next middleware, e.g. WithMaintenance or WithResource.
+func WithMetrics(app string) MiddlewareFunc {
+	statEvaluated, statActive, statDurations := metricEvaluated(), metricActive(), metricDurations()
 
 	return func(next Func) Func {
 		return func(ctx context.Context) error {
-			name, start, state := NameFromContext(ctx), time.Now(), "ok"
+			clock := clockFromContext(ctx)
+			name, start, state := NameFromContext(ctx), clock.Now(), "ok"
+
+			// exclude time spent waiting in middleware, e.g. for a lock
+			setRunState, _ := ctx.Value(stateFuncKey).(func(cronState))
+			ctx = context.WithValue(ctx, stateFuncKey, func(s cronState) {
+				if s == stateRunning {
+					start = clock.Now()
+				}
+				if setRunState != nil {
+					setRunState(s)
+				}
+			})
+			ctx = context.WithValue(ctx, metricsAppCtx, app)
+			if fn, ok := ctx.Value(metricsAppKey).(func(string)); ok {
+				fn(app)
+			}
 
 			statActive.WithLabelValues(app, name).Inc()
 			err := next(ctx)
-			if err != nil {
+			var se SkipError
+			switch {
+			case errors.As(err, &se):
+				state = "skipped_" + string(se.Kind)
+			case errors.Is(err, ErrSkipped):
+				state = "skipped"
+			case err != nil:
 				state = "error"
+				if class := errorClass(err); class != "" {
+					state += "_" + class
+				}
 			}
 
+			maintenance := strconv.FormatBool(MaintenanceFromContext(ctx))
 			statActive.WithLabelValues(app, name).Dec()
-			statEvaluated.WithLabelValues(app, name, state).Inc()
-			statDurations.WithLabelValues(app, name, state).Observe(time.Since(start).Seconds())
+			statEvaluated.WithLabelValues(app, name, state, maintenance).Inc()
+			statDurations.WithLabelValues(app, name, state, maintenance).Observe(clock.Since(start).Seconds())
 
 			return err
 		}
 	}
 }
+
+// metricsAppFromContext returns app name if WithMetrics is installed.
+func metricsAppFromContext(ctx context.Context) (string, bool) {
+	app, ok := ctx.Value(metricsAppCtx).(string)
+	return app, ok
+}
+
+// setMetricsApp saves app name of WithMetrics for metrics tracked by manager, e.g. app_cron_overrun_total.
+func (cm *Manager) setMetricsApp(idx int, app string) {
+	cm.muState.Lock()
+	defer cm.muState.Unlock()
+
+	cm.jobs[idx].metricsApp = app
+}
+
+// metricsApp returns app name of WithMetrics saved on job run or empty string.
+func (cm *Manager) metricsApp(idx int) string {
+	cm.muState.RLock()
+	defer cm.muState.RUnlock()
+
+	return cm.jobs[idx].metricsApp
+}
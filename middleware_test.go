@@ -0,0 +1,732 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithRecover(t *testing.T) {
+	Convey("Test recover middleware", t, func() {
+		f := WithRecover()(func(ctx context.Context) error {
+			panic("test panic")
+		})
+
+		err := f(t.Context())
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldEqual, "panic: test panic")
+
+		var pe *PanicError
+		So(errors.As(err, &pe), ShouldBeTrue)
+		So(string(pe.Stack), ShouldContainSubstring, "goroutine")
+	})
+}
+
+func TestWithMinInterval(t *testing.T) {
+	Convey("Test min interval middleware", t, func() {
+		m := NewManager()
+		m.Use(WithMinInterval(time.Hour))
+
+		var runs int
+		m.AddFunc("f1", "", func(ctx context.Context) error {
+			runs++
+			return nil
+		})
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		err := m.ManualRun(t.Context(), "f1")
+		So(errors.Is(err, ErrSkipped), ShouldBeTrue)
+		So(runs, ShouldEqual, 1)
+
+		st := m.State()[0]
+		So(st.LastState, ShouldEqual, "skipped")
+		So(st.SkipReason, ShouldContainSubstring, "eligible in")
+	})
+}
+
+func TestWithResultCache(t *testing.T) {
+	Convey("Test result cache middleware", t, func() {
+		clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+		m := NewManager(WithClock(clock))
+
+		var (
+			runs   int
+			config = "v1"
+			fail   error
+		)
+		m.AddFunc("f1", "", func(ctx context.Context) error {
+			runs++
+			return fail
+		}, JobMiddleware(WithResultCache(time.Hour, func(context.Context) string { return config })))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		clock.Add(10 * time.Minute)
+		So(errors.Is(m.ManualRun(t.Context(), "f1"), ErrSkipped), ShouldBeTrue)
+		So(m.State()[0].SkipReason, ShouldEqual, "cached success 10m0s ago, eligible in 50m0s")
+
+		config = "v2"
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		So(runs, ShouldEqual, 2)
+
+		// failed run invalidates cached success
+		config, fail = "v3", errors.New("failed")
+		So(m.ManualRun(t.Context(), "f1"), ShouldEqual, fail)
+		config, fail = "v2", nil
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		So(runs, ShouldEqual, 4)
+	})
+}
+
+func TestWithMaxRunsPer(t *testing.T) {
+	Convey("Test max runs per window middleware", t, func() {
+		var remaining []int
+		f := WithMaxRunsPer(time.Hour, 2)(func(ctx context.Context) error {
+			remaining = append(remaining, RemainingRunsFromContext(ctx))
+			return nil
+		})
+
+		ctx := NewNameContext(t.Context(), "f1-quota")
+		exceeded := metricDelta("app_cron_quota_exceeded_total", map[string]string{"app": "test-quota", "cron": "f1-quota"})
+		So(f(ctx), ShouldBeNil)
+		So(f(ctx), ShouldBeNil)
+		So(errors.Is(WithMetrics("test-quota")(f)(ctx), ErrSkipped), ShouldBeTrue)
+		So(exceeded(), ShouldEqual, 1)
+		So(remaining, ShouldResemble, []int{1, 0})
+
+		// other jobs have own quota
+		So(f(NewNameContext(t.Context(), "f2")), ShouldBeNil)
+		So(RemainingRunsFromContext(t.Context()), ShouldEqual, -1)
+
+		// invalid quota skips every run
+		var called bool
+		g := WithMaxRunsPer(time.Hour, 0)(func(context.Context) error { called = true; return nil })
+		So(errors.Is(g(ctx), ErrSkipped), ShouldBeTrue)
+		So(errors.Is(WithMaxRunsPer(0, 1)(g)(ctx), ErrSkipped), ShouldBeTrue)
+		So(called, ShouldBeFalse)
+	})
+}
+
+func TestWithSentry(t *testing.T) {
+	Convey("Test sentry scope", t, func() {
+		var events []*sentry.Event
+		So(sentry.Init(sentry.ClientOptions{BeforeSend: func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+			events = append(events, event)
+			return nil
+		}}), ShouldBeNil)
+		defer sentry.CurrentHub().BindClient(nil)
+
+		m := NewManager(WithName("billing"))
+		m.Use(WithDevel(true), WithRecover(), WithSentry())
+		m.AddMaintenanceFunc("m1", "", func(context.Context) error { panic("boom") })
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		So(m.ManualRun(t.Context(), "m1"), ShouldNotBeNil)
+		So(events, ShouldHaveLength, 1)
+		So(events[0].Tags, ShouldResemble, map[string]string{"cron": "m1", "maintenance": "true", "devel": "true", "manager": "billing"})
+		So(events[0].Contexts["cron"]["manager"], ShouldEqual, "billing")
+		So(events[0].Contexts["cron"]["duration"], ShouldNotBeEmpty)
+		So(events[0].Level, ShouldEqual, sentry.LevelError)
+
+		Convey("Test level classifier", func() {
+			errExpected := errors.New("expected")
+			m := NewManager()
+			m.Use(WithSentryOptions(SentryOptions{Level: func(err error) sentry.Level {
+				if errors.Is(err, errExpected) {
+					return sentry.LevelWarning
+				}
+				return sentry.LevelError
+			}}))
+			m.AddFunc("f1", "", func(context.Context) error { return errExpected })
+			So(m.Run(t.Context()), ShouldBeNil)
+
+			So(m.ManualRun(t.Context(), "f1"), ShouldNotBeNil)
+			So(events, ShouldHaveLength, 2)
+			So(events[1].Level, ShouldEqual, sentry.LevelWarning)
+		})
+	})
+}
+
+func TestWithSentryThrottle(t *testing.T) {
+	Convey("Test sentry throttling of identical errors", t, func() {
+		var events []*sentry.Event
+		So(sentry.Init(sentry.ClientOptions{BeforeSend: func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+			events = append(events, event)
+			return nil
+		}}), ShouldBeNil)
+		defer sentry.CurrentHub().BindClient(nil)
+
+		clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+		m := NewManager(WithClock(clock))
+		m.Use(WithSentryOptions(SentryOptions{Throttle: 10 * time.Minute, MaxPerHour: 3}))
+
+		var err error
+		m.AddFunc("f1", "", func(context.Context) error { return err })
+		m.AddFunc("f2", "", func(context.Context) error { panic("boom") })
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		run := func(msg string) {
+			err = errors.New(msg)
+			_ = m.ManualRun(t.Context(), "f1")
+			clock.Add(time.Minute)
+		}
+		run("db is down")
+		run("db is down")
+		run("db is down")
+		run("timeout")
+		So(events, ShouldHaveLength, 2)
+
+		clock.Add(10 * time.Minute)
+		run("db is down")
+		So(events, ShouldHaveLength, 3)
+		So(events[2].Extra["suppressed"], ShouldEqual, 2)
+
+		// hourly limit is reached
+		run("disk is full")
+		So(events, ShouldHaveLength, 3)
+
+		for range 3 {
+			So(m.ManualRun(t.Context(), "f2"), ShouldNotBeNil)
+		}
+		So(events, ShouldHaveLength, 6)
+	})
+
+	Convey("Test stale errors are pruned", t, func() {
+		th := newSentryThrottle(time.Minute, 0)
+		now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		for i := range 100 {
+			ok, _ := th.allow("f1", fmt.Sprintf("row %d not found", i), now)
+			So(ok, ShouldBeTrue)
+		}
+		So(th.errs, ShouldHaveLength, 100)
+
+		ok, _ := th.allow("f1", "row 0 not found", now.Add(time.Hour))
+		So(ok, ShouldBeTrue)
+		So(th.errs, ShouldHaveLength, 1)
+	})
+}
+
+func TestManager_Middleware(t *testing.T) {
+	Convey("Test middleware names and conflicts", t, func() {
+		lg := &testLogger{}
+		m := NewManager(WithManagerLogger(lg))
+		m.Use(WithRecover(), WithSentry(), WithSkipActive())
+		So(m.Middleware(), ShouldResemble, []string{"WithRecover", "WithSentry", "WithSkipActive"})
+
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+
+		msg, _ := lg.last()
+		So(msg, ShouldStartWith, "cron middleware conflict")
+
+		m2 := NewManager()
+		m2.Use(WithSentryOptions(SentryOptions{}), WithMaintenanceTimeout(nil, time.Second), WithResource("db", 1), WithChaos(0, 0, 0))
+		So(m2.Middleware(), ShouldResemble, []string{"WithSentry", "WithMaintenance", "WithResource", "WithChaos"})
+	})
+}
+
+func TestWithDeadlineFunc(t *testing.T) {
+	Convey("Test absolute deadline middleware", t, func() {
+		var deadline time.Time
+		f := WithDeadlineFunc(func(context.Context) time.Time { return deadline })(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		deadline = time.Now().Add(50 * time.Millisecond)
+		So(errors.Is(f(t.Context()), context.DeadlineExceeded), ShouldBeTrue)
+		So(time.Now(), ShouldHappenOnOrAfter, deadline)
+
+		deadline = time.Now().Add(-time.Minute)
+		So(errors.Is(f(t.Context()), context.DeadlineExceeded), ShouldBeTrue)
+
+		deadline = time.Time{}
+		ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		defer cancel()
+		So(errors.Is(f(ctx), context.DeadlineExceeded), ShouldBeTrue)
+	})
+}
+
+// metricValue returns counter or gauge value from default prometheus registry.
+// metricDelta returns func which reports metric change since metricDelta call, so assertions survive -count=N.
+func metricDelta(name string, labels map[string]string) func() float64 {
+	before := metricValue(name, labels)
+	return func() float64 { return metricValue(name, labels) - before }
+}
+
+func metricValue(name string, labels map[string]string) float64 {
+	mfs, _ := prometheus.DefaultGatherer.Gather()
+	for _, mf := range mfs {
+		if mf.GetName() != name {
+			continue
+		}
+
+	metrics:
+		for _, m := range mf.GetMetric() {
+			for _, lp := range m.GetLabel() {
+				if v, ok := labels[lp.GetName()]; ok && v != lp.GetValue() {
+					continue metrics
+				}
+			}
+			if g := m.GetGauge(); g != nil {
+				return g.GetValue()
+			}
+			return m.GetCounter().GetValue()
+		}
+	}
+
+	return 0
+}
+
+func TestWithMetrics_Maintenance(t *testing.T) {
+	Convey("Test maintenance label in metrics", t, func() {
+		m := NewManager()
+		m.Use(WithMetrics("test-maintenance"))
+		f1 := metricDelta("app_cron_evaluated_total", map[string]string{"app": "test-maintenance", "cron": "f1", "maintenance": "false"})
+		f2 := metricDelta("app_cron_evaluated_total", map[string]string{"app": "test-maintenance", "cron": "f2", "maintenance": "true"})
+		m.AddFunc("f1", "", newCronFunc("f1"))
+		m.AddMaintenanceFunc("f2", "", newCronFunc("f2"))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		So(m.ManualRun(t.Context(), "f2"), ShouldBeNil)
+
+		So(f1(), ShouldEqual, 1)
+		So(f2(), ShouldEqual, 1)
+		So(metricValue("app_cron_evaluated_total", map[string]string{"app": "test-maintenance", "cron": "f2", "maintenance": "false"}), ShouldEqual, 0)
+	})
+}
+
+func TestWithIgnoreErrors(t *testing.T) {
+	Convey("Test ignored errors are successful runs for metrics", t, func() {
+		errNothing := errors.New("nothing to do")
+		lg := &testDebugLogger{}
+		m := NewManager(WithManagerLogger(lg))
+		ok := metricDelta("app_cron_evaluated_total", map[string]string{"app": "test-ignore", "cron": "f1", "state": "ok"})
+		failed := metricDelta("app_cron_evaluated_total", map[string]string{"app": "test-ignore", "cron": "f1", "state": "error"})
+		m.Use(WithMetrics("test-ignore"), WithIgnoreErrors(ErrorIs(errNothing), ErrorContains("no rows")))
+
+		var err error
+		m.AddFunc("f1", "", func(context.Context) error { return err })
+		m.AddFunc("f2", "", func(context.Context) error { return errNothing },
+			JobMiddleware(WithSkipErrors(ErrorIs(errNothing))))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		err = fmt.Errorf("load: %w", errNothing)
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		msg, args := lg.last()
+		So(msg, ShouldEqual, "cron job error ignored")
+		So(args, ShouldResemble, []any{"job", "f1", "err", err})
+		err = errors.New("sql: no rows in result set")
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		err = errors.New("failed")
+		So(m.ManualRun(t.Context(), "f1"), ShouldEqual, err)
+
+		So(ok(), ShouldEqual, 2)
+		So(failed(), ShouldEqual, 1)
+
+		So(errors.Is(m.ManualRun(t.Context(), "f2"), ErrSkipped), ShouldBeTrue)
+		So(m.State()[1].SkipReason, ShouldEqual, "nothing to do")
+	})
+}
+
+// testDebugLogger is testLogger which logs only at debug level.
+type testDebugLogger struct {
+	testLogger
+}
+
+func (l *testDebugLogger) Print(context.Context, string, ...any) {}
+func (l *testDebugLogger) Error(context.Context, string, ...any) {}
+func (l *testDebugLogger) Debug(_ context.Context, msg string, args ...any) {
+	l.log(msg, args)
+}
+
+func TestWithTokenBucket(t *testing.T) {
+	Convey("Test token bucket shared between jobs", t, func() {
+		m := NewManager()
+		limit := WithTokenBucket(10, 1)
+		m.AddFunc("f1", "", newCronFunc("f1"), JobMiddleware(limit))
+		m.AddFunc("f2", "", newCronFunc("f2"), JobMiddleware(limit))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		start := time.Now()
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		So(m.ManualRun(t.Context(), "f2"), ShouldBeNil)
+		So(time.Since(start), ShouldBeGreaterThanOrEqualTo, 90*time.Millisecond)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		So(m.ManualRun(ctx, "f1"), ShouldNotBeNil)
+	})
+}
+
+func TestWithDelay(t *testing.T) {
+	Convey("Test delayed start is excluded from duration", t, func() {
+		clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC), after: make(chan time.Duration)}
+		m := NewManager(WithClock(clock))
+		m.AddFunc("f1", "", func(context.Context) error {
+			clock.Add(time.Second)
+			return nil
+		}, JobMiddleware(WithDelay(time.Minute)))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		done := make(chan error)
+		go func() { done <- m.ManualRun(t.Context(), "f1") }()
+		So(<-clock.after, ShouldEqual, time.Minute)
+		So(m.State()[0].LastState, ShouldEqual, "waiting")
+
+		clock.Add(time.Minute)
+		So(<-done, ShouldBeNil)
+		st := m.State()[0]
+		So(st.LastState, ShouldEqual, "idle")
+		So(st.LastDuration, ShouldEqual, time.Second)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		go func() { <-clock.after }()
+		So(errors.Is(m.ManualRun(ctx, "f1"), context.Canceled), ShouldBeTrue)
+	})
+}
+
+func TestWithRateLimit(t *testing.T) {
+	Convey("Test rate limit middleware", t, func() {
+		clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 30, 0, time.UTC)}
+		m := NewManager(WithClock(clock), WithManualTicker())
+		m.Use(WithRateLimit(90 * time.Second))
+
+		var runs int
+		m.AddFunc("f1", "* * * * *", func(context.Context) error {
+			runs++
+			return errors.New("failed")
+		})
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		// scheduled run limits manual run
+		clock.Add(30 * time.Second)
+		So(m.Tick(t.Context(), clock.Now()), ShouldNotBeNil)
+		clock.Add(30 * time.Second)
+		So(errors.Is(m.ManualRun(t.Context(), "f1"), ErrSkipped), ShouldBeTrue)
+		So(m.State()[0].SkipReason, ShouldEqual, "rate limited: started 30s ago, eligible in 1m0s")
+
+		// next scheduled run is limited too, failed runs count
+		clock.Add(30 * time.Second)
+		So(errors.Is(m.Tick(t.Context(), clock.Now()), ErrSkipped), ShouldBeTrue)
+
+		// manual run limits scheduled run
+		clock.Add(30 * time.Second)
+		So(m.ManualRun(t.Context(), "f1"), ShouldNotBeNil)
+		clock.Add(30 * time.Second)
+		So(errors.Is(m.Tick(t.Context(), clock.Now()), ErrSkipped), ShouldBeTrue)
+		So(runs, ShouldEqual, 2)
+	})
+}
+
+func TestWithBackoff(t *testing.T) {
+	Convey("Test backoff middleware", t, func() {
+		clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+		m := NewManager(WithClock(clock))
+		m.Use(WithBackoff())
+
+		var runs int
+		m.AddFunc("f1", "", func(context.Context) error {
+			runs++
+			return BackoffError{RetryAfter: 10 * time.Minute, Err: errors.New("rate limited")}
+		})
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		err := m.ManualRun(t.Context(), "f1")
+		So(err.Error(), ShouldEqual, "backoff requested: rate limited")
+
+		clock.Add(5 * time.Minute)
+		So(errors.Is(m.ManualRun(t.Context(), "f1"), ErrSkipped), ShouldBeTrue)
+		So(m.State()[0].SkipReason, ShouldEqual, "backoff until 2025-01-01 12:10:00")
+		So(runs, ShouldEqual, 1)
+
+		clock.Add(5 * time.Minute)
+		So(m.ManualRun(t.Context(), "f1"), ShouldNotBeNil)
+		So(runs, ShouldEqual, 2)
+	})
+}
+
+func TestWithMaintenance(t *testing.T) {
+	Convey("Test maintenance middleware waiting state", t, func() {
+		m := NewManager()
+		m.Use(WithMaintenance(nil))
+
+		release := make(chan struct{})
+		m.AddMaintenanceFunc("m1", "", func(ctx context.Context) error {
+			<-release
+			return nil
+		})
+		m.AddFunc("f1", "", newCronFunc("f1"))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		wait := watchStates(m)
+		done1 := manualRun(t.Context(), m, "m1")
+		wait("m1", "running")
+		done2 := manualRun(t.Context(), m, "f1")
+		wait("f1", "waiting")
+
+		st := m.State()
+		So(st[0].LastState, ShouldEqual, "running")
+		So(st[1].LastState, ShouldEqual, "waiting")
+		So(m.Running(), ShouldResemble, []string{"m1", "f1"})
+
+		close(release)
+		So(<-done1, ShouldBeNil)
+		So(<-done2, ShouldBeNil)
+		st = m.State()
+		So(st[0].LastState, ShouldEqual, "idle")
+		So(st[1].LastState, ShouldEqual, "idle")
+		So(m.Running(), ShouldBeEmpty)
+	})
+}
+
+func TestWithMaintenanceOptions(t *testing.T) {
+	Convey("Test maintenance lock wait and hold time", t, func() {
+		var (
+			mu    sync.Mutex
+			waits = make(map[string]time.Duration)
+			helds = make(map[string]time.Duration)
+			lines []string
+		)
+		lg := &testLogger{}
+		m := NewManager()
+		m.Use(WithSLog(lg), WithMaintenanceOptions(MaintenanceOptions{
+			Logger: func(format string, v ...any) {
+				mu.Lock()
+				defer mu.Unlock()
+				lines = append(lines, strings.Fields(fmt.Sprintf(format, v...))[1])
+			},
+			OnRelease: func(ctx context.Context, wait, held time.Duration) {
+				mu.Lock()
+				defer mu.Unlock()
+				waits[NameFromContext(ctx)], helds[NameFromContext(ctx)] = wait, held
+			},
+		}))
+
+		var ctxWait atomic.Int64
+		m.AddMaintenanceFunc("m1", "", func(context.Context) error {
+			time.Sleep(100 * time.Millisecond)
+			return nil
+		})
+		m.AddFunc("f1", "", func(ctx context.Context) error {
+			ctxWait.Store(int64(MaintenanceWaitFromContext(ctx)))
+			return nil
+		})
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		wait := watchStates(m)
+		before := lockWaitCount("f1")
+		done := manualRun(t.Context(), m, "m1")
+		wait("m1", "running")
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		So(<-done, ShouldBeNil)
+
+		mu.Lock()
+		defer mu.Unlock()
+		So(waits["m1"], ShouldBeLessThan, 20*time.Millisecond)
+		So(helds["m1"], ShouldBeGreaterThanOrEqualTo, 100*time.Millisecond)
+		So(waits["f1"], ShouldBeGreaterThanOrEqualTo, 60*time.Millisecond)
+		So(time.Duration(ctxWait.Load()), ShouldEqual, waits["f1"])
+
+		_, args := lg.last()
+		So(args, ShouldContain, "lockWait")
+		So(args[3], ShouldBeLessThan, 20*time.Millisecond) // duration without wait
+
+		So(lockWaitCount("f1")-before, ShouldEqual, 1)
+		So(lines, ShouldResemble, []string{"getting", "got", "released"})
+	})
+}
+
+// lockWaitCount returns number of read lock waits of job without WithMetrics in app_cron_lock_wait_seconds.
+func lockWaitCount(job string) uint64 {
+	var pm dto.Metric
+	_ = metricLockWait().WithLabelValues("", job, "read").(prometheus.Metric).Write(&pm)
+	return pm.GetSummary().GetSampleCount()
+}
+
+func TestWithMaintenanceTimeout(t *testing.T) {
+	Convey("Test maintenance lock acquire timeout", t, func() {
+		var lines []string
+		m := NewManager()
+		m.Use(WithMaintenanceTimeout(func(format string, v ...any) { lines = append(lines, fmt.Sprintf(format, v...)) }, 50*time.Millisecond))
+
+		release := make(chan struct{})
+		m.AddMaintenanceFunc("m1", "", func(context.Context) error {
+			<-release
+			return nil
+		})
+		m.AddFunc("f1", "", newCronFunc("f1"))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		wait := watchStates(m)
+		done := manualRun(t.Context(), m, "m1")
+		wait("m1", "running")
+
+		err := m.ManualRun(t.Context(), "f1")
+		So(errors.Is(err, ErrSkipped), ShouldBeTrue)
+		So(m.State()[1].SkipReason, ShouldEqual, "maintenance lock wait timeout 50ms")
+
+		close(release)
+		<-done
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		So(lines, ShouldContain, "cron skipped job=f1, maintenance lock wait timeout=50ms")
+	})
+}
+
+func TestWithSkipActive(t *testing.T) {
+	Convey("Test skip active middleware with opt-out", t, func() {
+		m := NewManager()
+		m.Use(WithMetrics("test-skip"), WithSkipActive())
+		wait := watchStates(m)
+		active := metricDelta("app_cron_evaluated_total", map[string]string{"app": "test-skip", "cron": "f1", "state": "skipped_active"})
+		skipped := metricDelta("app_cron_evaluated_total", map[string]string{"app": "test-skip", "cron": "f3", "state": "skipped"})
+
+		release := make(chan struct{})
+		block := func(ctx context.Context) error {
+			<-release
+			return nil
+		}
+		m.AddFunc("f1", "", block)
+		m.AddFunc("f2", "", block, NoSkipActive())
+		m.AddFunc("f3", "", func(context.Context) error { return ErrSkipped })
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		done1, done2 := manualRun(t.Context(), m, "f1"), manualRun(t.Context(), m, "f2")
+		wait("f1", "running")
+		wait("f2", "running")
+
+		err := m.ManualRun(t.Context(), "f1")
+		So(errors.Is(err, ErrSkipped), ShouldBeTrue)
+		So(err, ShouldResemble, SkipError{Kind: SkipActive, Reason: "already running"})
+		So(m.State()[0].SkipKind, ShouldEqual, SkipActive)
+		So(active(), ShouldEqual, 1)
+
+		So(m.ManualRun(t.Context(), "f3"), ShouldEqual, ErrSkipped)
+		So(skipped(), ShouldEqual, 1)
+
+		done := manualRun(t.Context(), m, "f2")
+		close(release)
+		So(<-done, ShouldBeNil)
+		So(<-done1, ShouldBeNil)
+		So(<-done2, ShouldBeNil)
+	})
+}
+
+type testLogger struct {
+	mu   sync.Mutex
+	msg  string
+	args []any
+}
+
+func (l *testLogger) Print(_ context.Context, msg string, args ...any) { l.log(msg, args) }
+func (l *testLogger) Error(_ context.Context, msg string, args ...any) { l.log(msg, args) }
+
+func (l *testLogger) log(msg string, args []any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.msg, l.args = msg, args
+}
+
+func (l *testLogger) last() (string, []any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.msg, l.args
+}
+
+func TestWithSLog(t *testing.T) {
+	Convey("Test slog middleware attributes", t, func() {
+		lg := &testLogger{}
+		m := NewManager(WithName("test"))
+		m.Use(WithSLog(lg))
+		m.AddMaintenanceFunc("f1", "", newCronFunc("f1"))
+		So(m.Run(t.Context()), ShouldBeNil)
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+
+		msg, args := lg.last()
+		So(msg, ShouldEqual, "cron job finished")
+		So(args, ShouldContain, "maintenance")
+		So(args, ShouldContain, true)
+		So(args, ShouldContain, "manager")
+		So(args, ShouldContain, "test")
+	})
+}
+
+func TestEnvironmentGate(t *testing.T) {
+	Convey("Test environment gated jobs", t, func() {
+		m := NewManager()
+		m.Use(WithDevel(true))
+		m.AddFunc("devel", "", newCronFunc("devel"), OnlyInDevel())
+		m.AddFunc("prod", "", newCronFunc("prod"), SkipInDevel())
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		So(m.ManualRun(t.Context(), "devel"), ShouldBeNil)
+		So(errors.Is(m.ManualRun(t.Context(), "prod"), ErrSkipped), ShouldBeTrue)
+
+		st := m.State()
+		So(st[0].Environment, ShouldEqual, "devel")
+		So(st[1].Environment, ShouldEqual, "production")
+		So(st[1].SkipReason, ShouldEqual, "environment: production only")
+	})
+}
+
+func TestWithContextValues(t *testing.T) {
+	Convey("Test context values middleware", t, func() {
+		type tenantKey struct{}
+
+		m := NewManager()
+		m.Use(
+			WithDevel(true),
+			WithContextValues(func(ctx context.Context) context.Context {
+				return context.WithValue(ctx, tenantKey{}, "t-"+NameFromContext(ctx))
+			}),
+		)
+
+		var tenant any
+		var isDevel bool
+		m.AddFunc("f1", "", func(ctx context.Context) error {
+			tenant, isDevel = ctx.Value(tenantKey{}), IsDevelFromContext(ctx)
+			return nil
+		})
+		So(m.Run(t.Context()), ShouldBeNil)
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		So(tenant, ShouldEqual, "t-f1")
+		So(isDevel, ShouldBeTrue)
+	})
+}
+
+func TestWithSingleFlight(t *testing.T) {
+	Convey("Test single flight middleware", t, func() {
+		var runs atomic.Int32
+		release := make(chan struct{})
+		f := WithSingleFlight()(func(context.Context) error {
+			runs.Add(1)
+			<-release
+			return errors.New("shared")
+		})
+
+		ctx := NewNameContext(t.Context(), "f1")
+		errs := make(chan error, 2)
+		go func() { errs <- f(ctx) }()
+		time.Sleep(50 * time.Millisecond)
+		go func() { errs <- f(ctx) }()
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+
+		So((<-errs).Error(), ShouldEqual, "shared")
+		So((<-errs).Error(), ShouldEqual, "shared")
+		So(runs.Load(), ShouldEqual, 1)
+	})
+}
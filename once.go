@@ -0,0 +1,85 @@
+package cron
+
+import (
+	"context"
+	"sync"
+)
+
+const onceKey contextKey = "once"
+
+// Once is a store of WithOnce middleware. It latches jobs after their first successful run.
+type Once struct {
+	mu      sync.Mutex
+	done    map[string]bool
+	running map[string]bool // runs in progress before the job is latched
+}
+
+// NewOnce returns new store for WithOnce middleware.
+func NewOnce() *Once {
+	return &Once{done: make(map[string]bool), running: make(map[string]bool)}
+}
+
+// WithOnce runs the job until it first succeeds and then skips all further runs with "already completed" reason
+// until process restart or Once.Reset. Failed runs don't latch the job, so the schedule works as a retry.
+// Only one run of the job is allowed until it is latched, concurrent runs are skipped with "already running" reason.
+// Latched jobs are kept in o, use nil if Once.Done and Once.Reset are not needed:
+//
+//	once := cron.NewOnce()
+//	m.AddFunc("warmup", "*/5 * * * *", warmup, cron.JobMiddleware(cron.WithOnce(once)))
+func WithOnce(o *Once) MiddlewareFunc {
+	if o == nil {
+		o = NewOnce()
+	}
+
+	return func(next Func) Func {
+		return func(ctx context.Context) error {
+			name := NameFromContext(ctx)
+
+			o.mu.Lock()
+			switch {
+			case o.done[name]:
+				o.mu.Unlock()
+				return newSkipError(SkipDisabled, "already completed")
+			case o.running[name]:
+				o.mu.Unlock()
+				return newSkipError(SkipActive, "already running")
+			}
+			o.running[name] = true
+			o.mu.Unlock()
+
+			err := next(context.WithValue(ctx, onceKey, o))
+
+			o.mu.Lock()
+			delete(o.running, name)
+			if err == nil {
+				o.done[name] = true
+			}
+			o.mu.Unlock()
+
+			return err
+		}
+	}
+}
+
+// Done checks if the job has completed successfully.
+func (o *Once) Done(name string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.done[name]
+}
+
+// Reset unlatches the job, so it runs again on the next trigger.
+func (o *Once) Reset(name string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	delete(o.done, name)
+}
+
+// OnceFromContext returns WithOnce store of the running job or nil if the job has no WithOnce middleware.
+// Use it to check latched status of jobs or to unlatch them from the job.
+func OnceFromContext(ctx context.Context) *Once {
+	o, _ := ctx.Value(onceKey).(*Once)
+	return o
+}
@@ -0,0 +1,68 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithOnce(t *testing.T) {
+	Convey("Test job runs until first success", t, func() {
+		once := NewOnce()
+		m := NewManager()
+		m.Use(WithOnce(once))
+
+		var (
+			runs int
+			fail = errors.New("failed")
+		)
+		m.AddFunc("f1", "", func(ctx context.Context) error {
+			runs++
+			if o := OnceFromContext(ctx); o != once || o.Done("f1") {
+				return errors.New("unexpected once store")
+			}
+			return fail
+		})
+		So(m.Run(t.Context()), ShouldBeNil)
+		So(m.Middleware(), ShouldResemble, []string{"WithOnce"})
+
+		So(m.ManualRun(t.Context(), "f1"), ShouldEqual, fail)
+		So(once.Done("f1"), ShouldBeFalse)
+
+		fail = nil
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		So(once.Done("f1"), ShouldBeTrue)
+		So(errors.Is(m.ManualRun(t.Context(), "f1"), ErrSkipped), ShouldBeTrue)
+		So(m.State()[0].SkipReason, ShouldEqual, "already completed")
+		So(runs, ShouldEqual, 2)
+
+		once.Reset("f1")
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		So(runs, ShouldEqual, 3)
+		So(OnceFromContext(t.Context()), ShouldBeNil)
+	})
+
+	Convey("Test concurrent runs before first success", t, func() {
+		started, release := make(chan struct{}), make(chan struct{})
+		f := WithOnce(nil)(func(context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+
+		ctx := NewNameContext(t.Context(), "f1")
+		done := make(chan error)
+		go func() { done <- f(ctx) }()
+		<-started
+
+		err := f(ctx)
+		So(errors.Is(err, ErrSkipped), ShouldBeTrue)
+		So(err.Error(), ShouldContainSubstring, "already running")
+
+		close(release)
+		So(<-done, ShouldBeNil)
+		So(errors.Is(f(ctx), ErrSkipped), ShouldBeTrue)
+	})
+}
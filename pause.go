@@ -0,0 +1,57 @@
+package cron
+
+// PauseAll stops scheduling of all jobs, e.g. during deploys: robfig/cron entries are removed and
+// schedules are kept to be restored by ResumeAll. Running jobs are not stopped, manual runs are not affected.
+// Unlike Drain, paused jobs have no next runs.
+func (cm *Manager) PauseAll() {
+	cm.muState.Lock()
+	if cm.paused {
+		cm.muState.Unlock()
+		return
+	}
+
+	cm.paused = true
+	var ids []int
+	for i, j := range cm.jobs {
+		if j.sched != nil && !j.autoDisabled {
+			cm.cron.Remove(j.id)
+			ids = append(ids, i)
+		}
+	}
+	cm.muState.Unlock()
+
+	for _, idx := range ids {
+		cm.notifyState(idx)
+	}
+}
+
+// ResumeAll restores scheduling of all jobs after PauseAll. Jobs disabled by DisableAfterFailures stay disabled.
+func (cm *Manager) ResumeAll() {
+	cm.muState.Lock()
+	if !cm.paused {
+		cm.muState.Unlock()
+		return
+	}
+
+	cm.paused = false
+	var ids []int
+	for i, j := range cm.jobs {
+		if j.sched != nil && !j.autoDisabled {
+			ids = append(ids, i)
+		}
+	}
+	cm.muState.Unlock()
+
+	for _, idx := range ids {
+		cm.schedule(idx)
+		cm.notifyState(idx)
+	}
+}
+
+// Paused returns true if scheduling is paused by PauseAll.
+func (cm *Manager) Paused() bool {
+	cm.muState.RLock()
+	defer cm.muState.RUnlock()
+
+	return cm.paused
+}
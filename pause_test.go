@@ -0,0 +1,57 @@
+package cron
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestManager_PauseAll(t *testing.T) {
+	Convey("Test pause and resume all jobs", t, func() {
+		Convey("Test entries are removed and restored", func() {
+			m := NewManager()
+			m.AddFunc("f1", "* * * * *", newCronFunc("f1"))
+			m.AddFunc("f2", "", newCronFunc("f2"))
+			So(m.Run(t.Context()), ShouldBeNil)
+			defer m.Stop()
+			So(m.State()[0].NextRun.IsZero(), ShouldBeFalse)
+
+			m.PauseAll()
+			So(m.Paused(), ShouldBeTrue)
+			st := m.State()[0]
+			So(st.Paused, ShouldBeTrue)
+			So(st.Schedule, ShouldEqual, "* * * * *")
+			So(st.NextRun.IsZero(), ShouldBeTrue)
+			So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+
+			m.ResumeAll()
+			So(m.Paused(), ShouldBeFalse)
+			So(m.State()[0].NextRun.IsZero(), ShouldBeFalse)
+			So(m.State()[1].LastState, ShouldEqual, "unscheduled")
+		})
+
+		Convey("Test ticks are suppressed", func() {
+			clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 30, 0, time.UTC)}
+			m := NewManager(WithClock(clock), WithManualTicker())
+
+			var runs int
+			m.AddFunc("f1", "* * * * *", func(context.Context) error {
+				runs++
+				return nil
+			})
+			So(m.Run(t.Context()), ShouldBeNil)
+
+			m.PauseAll()
+			clock.Add(time.Minute)
+			So(m.Tick(t.Context(), clock.Now()), ShouldBeNil)
+			So(runs, ShouldEqual, 0)
+
+			m.ResumeAll()
+			clock.Add(time.Minute)
+			So(m.Tick(t.Context(), clock.Now()), ShouldBeNil)
+			So(runs, ShouldEqual, 1)
+		})
+	})
+}
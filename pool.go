@@ -0,0 +1,111 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+)
+
+// poolItem is a scheduled run queued to worker pool.
+type poolItem struct {
+	ctx context.Context
+	idx int
+	fn  Func
+}
+
+// workerPool runs scheduled jobs with a fixed number of workers, see WithWorkerPool.
+type workerPool struct {
+	size  int
+	queue chan poolItem
+}
+
+// WithWorkerPool runs scheduled jobs with at most size workers instead of a goroutine per run.
+// Runs (including catch-up and RunOnStart runs) are queued while all workers are busy, a run is skipped if queue is full.
+// Manual runs are not affected.
+// Pool size and queue depth are shown in Summary and UI and tracked in app_cron_pool_size and app_cron_pool_queue metrics.
+func WithWorkerPool(size, queue int) Option {
+	return func(cm *Manager) {
+		if size < 1 || queue < 0 {
+			cm.err = fmt.Errorf("invalid worker pool size=%d queue=%d", size, queue)
+			return
+		}
+
+		cm.pool = &workerPool{size: size, queue: make(chan poolItem, queue)}
+	}
+}
+
+// startPool starts pool workers, they are stopped when ctx is done.
+func (cm *Manager) startPool(ctx context.Context) {
+	if cm.pool == nil {
+		return
+	}
+
+	metricPoolSize().WithLabelValues(cm.name).Set(float64(cm.pool.size))
+	cm.wgBackground.Add(cm.pool.size)
+	for range cm.pool.size {
+		go func() {
+			defer cm.wgBackground.Done()
+			cm.poolWorker(ctx)
+		}()
+	}
+}
+
+// dropQueued removes queued runs from worker pool, workers must be stopped.
+func (cm *Manager) dropQueued() {
+	if cm.pool == nil {
+		return
+	}
+
+	for {
+		select {
+		case <-cm.pool.queue:
+		default:
+			metricPoolQueue().WithLabelValues(cm.name).Set(0)
+			return
+		}
+	}
+}
+
+// poolWorker runs queued jobs until ctx is done.
+func (cm *Manager) poolWorker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case it := <-cm.pool.queue:
+			metricPoolQueue().WithLabelValues(cm.name).Set(float64(len(cm.pool.queue)))
+			_ = it.fn(it.ctx)
+		}
+	}
+}
+
+// enqueue queues scheduled run to worker pool or skips it if queue is full. Queued job is in queued state
+// unless it is already running.
+func (cm *Manager) enqueue(ctx context.Context, idx int, fn Func) {
+	select {
+	case cm.pool.queue <- poolItem{ctx: ctx, idx: idx, fn: fn}:
+		metricPoolQueue().WithLabelValues(cm.name).Set(float64(len(cm.pool.queue)))
+		if !cm.isRunning(idx) {
+			cm.updateState(idx, stateQueued, nil)
+		}
+	default:
+		cm.skipRun(idx, newSkipError(SkipLimit, "worker pool queue is full: %d runs queued", cap(cm.pool.queue)))
+		if cm.logger != nil {
+			cm.logger.Print(ctx, "cron skipped job, worker pool queue is full", "job", cm.jobs[idx].name)
+		}
+	}
+}
+
+// runScheduled starts scheduled run of the job in worker pool if WithWorkerPool is set or in its own goroutine.
+func (cm *Manager) runScheduled(ctx context.Context, idx int) {
+	fn := cm.jobs[idx].schedFn
+	if cm.pool != nil {
+		cm.enqueue(ctx, idx, fn)
+		return
+	}
+
+	cm.wgBackground.Add(1)
+	go func() {
+		defer cm.wgBackground.Done()
+		_ = fn(ctx)
+	}()
+}
@@ -0,0 +1,106 @@
+package cron
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithWorkerPool(t *testing.T) {
+	Convey("Test worker pool", t, func() {
+		started := make(chan string, 3)
+		release := make(chan struct{})
+		fn := func(ctx context.Context) error {
+			started <- NameFromContext(ctx)
+			<-release
+			return nil
+		}
+
+		m := NewManager(WithWorkerPool(1, 1))
+		m.AddFunc("f1", "@yearly", fn)
+		m.AddFunc("f2", "@yearly", fn)
+		m.AddFunc("f3", "@yearly", fn)
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+
+		m.enqueue(t.Context(), 0, m.jobs[0].schedFn)
+		So(<-started, ShouldEqual, "f1")
+
+		m.enqueue(t.Context(), 1, m.jobs[1].schedFn)
+		sm := m.Summary()
+		So(sm.PoolSize, ShouldEqual, 1)
+		So(sm.PoolQueue, ShouldEqual, 1)
+		st := m.State()
+		So(st[1].LastState, ShouldEqual, "queued")
+
+		m.enqueue(t.Context(), 2, m.jobs[2].schedFn)
+		st = m.State()
+		So(st[2].LastState, ShouldEqual, "skipped")
+		So(st[2].SkipKind, ShouldEqual, SkipLimit)
+
+		// running job keeps its state
+		m.enqueue(t.Context(), 0, m.jobs[0].schedFn)
+		st = m.State()
+		So(st[0].LastState, ShouldEqual, "running")
+		So(st[0].SkipKind, ShouldEqual, SkipLimit)
+
+		close(release)
+		select {
+		case name := <-started:
+			So(name, ShouldEqual, "f2")
+		case <-time.After(time.Second):
+			So("timeout", ShouldBeEmpty)
+		}
+		So(m.WaitUntilIdle(t.Context()), ShouldBeNil)
+		So(m.Summary().PoolQueue, ShouldEqual, 0)
+	})
+
+	Convey("Test run on start goes through worker pool", t, func() {
+		release := make(chan struct{})
+		m := NewManager(WithWorkerPool(1, 1))
+		for _, name := range []string{"f1", "f2", "f3"} {
+			m.AddFunc(name, "@yearly", func(context.Context) error {
+				<-release
+				return nil
+			}, RunOnStart())
+		}
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+
+		var skipped int
+		for _, st := range m.State() {
+			if st.SkipKind == SkipLimit {
+				skipped++
+			}
+		}
+		So(skipped, ShouldBeGreaterThanOrEqualTo, 1)
+
+		close(release)
+		So(m.WaitUntilIdle(t.Context()), ShouldBeNil)
+	})
+
+	Convey("Test stop stops pool workers", t, func() {
+		var runs atomic.Int32
+		m := NewManager(WithWorkerPool(2, 1))
+		m.AddFunc("f1", "@yearly", func(context.Context) error {
+			runs.Add(1)
+			return nil
+		})
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		<-m.Stop().Done()
+		m.wgBackground.Wait()
+
+		m.enqueue(t.Context(), 0, m.jobs[0].schedFn)
+		So(m.State()[0].LastState, ShouldEqual, "queued")
+		So(runs.Load(), ShouldEqual, 0)
+	})
+
+	Convey("Test invalid worker pool", t, func() {
+		m := NewManager(WithWorkerPool(0, 1))
+		So(m.Run(t.Context()), ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,107 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// pushTimeout limits a single push, so unavailable Pushgateway doesn't hold pushes forever.
+const pushTimeout = 10 * time.Second
+
+// metricsPusher pushes metrics to Prometheus Pushgateway, see WithMetricsPush.
+type metricsPusher struct {
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+	pending  atomic.Bool
+	url      string
+	job      string
+	user     *url.Userinfo
+	instance string
+}
+
+// WithMetricsPush pushes WithMetrics metrics (evaluated, active and duration) to Pushgateway after each run
+// and on Stop, e.g. for CLI tools which exit before scrape. Basic auth is taken from gatewayURL user info.
+// Metrics are grouped by job, manager name (see WithName) and instance (hostname). Pushes after runs are made
+// in background and coalesced, Stop waits for them and pushes once more. Each push is limited by 10 seconds.
+// Push errors are logged via WithManagerLogger and don't affect runs.
+func WithMetricsPush(gatewayURL, job string) Option {
+	return func(cm *Manager) {
+		u, err := url.Parse(gatewayURL)
+		if err != nil {
+			cm.err = fmt.Errorf("invalid pushgateway url=%q: %w", gatewayURL, err)
+			return
+		}
+
+		instance, _ := os.Hostname()
+		p := &metricsPusher{job: job, user: u.User, instance: instance}
+		u.User = nil
+		p.url = u.String()
+
+		cm.pusher = p
+	}
+}
+
+// push pushes WithMetrics metrics with manager and instance grouping labels, p.mu must be held.
+func (p *metricsPusher) push(ctx context.Context, manager string) error {
+	ctx, cancel := context.WithTimeout(ctx, pushTimeout)
+	defer cancel()
+
+	pp := push.New(p.url, p.job).
+		Collector(metricEvaluated()).Collector(metricActive()).Collector(metricDurations()).
+		Grouping("instance", p.instance)
+	if manager != "" {
+		pp = pp.Grouping("manager", manager)
+	}
+	if p.user != nil {
+		password, _ := p.user.Password()
+		pp = pp.BasicAuth(p.user.Username(), password)
+	}
+
+	return pp.PushContext(ctx)
+}
+
+// pushMetrics pushes metrics to Pushgateway in background if WithMetricsPush is set.
+// Runs finished while push is waiting for previous one are coalesced into it.
+func (cm *Manager) pushMetrics(ctx context.Context) {
+	p := cm.pusher
+	if p == nil || !p.pending.CompareAndSwap(false, true) {
+		return
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		p.pending.Store(false)
+		cm.logPushErr(ctx, p.push(context.WithoutCancel(ctx), cm.name))
+	}()
+}
+
+// flushMetrics waits for background pushes and pushes metrics to Pushgateway if WithMetricsPush is set.
+func (cm *Manager) flushMetrics(ctx context.Context) {
+	p := cm.pusher
+	if p == nil {
+		return
+	}
+
+	p.wg.Wait()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cm.logPushErr(ctx, p.push(context.WithoutCancel(ctx), cm.name))
+}
+
+func (cm *Manager) logPushErr(ctx context.Context, err error) {
+	if err != nil && cm.logger != nil {
+		cm.logger.Error(ctx, "cron metrics push failed", "err", err)
+	}
+}
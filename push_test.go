@@ -0,0 +1,83 @@
+package cron
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithMetricsPush(t *testing.T) {
+	Convey("Test metrics are pushed to pushgateway", t, func() {
+		var (
+			mu    sync.Mutex
+			paths []string
+			users []string
+		)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			user, _, _ := r.BasicAuth()
+			paths, users = append(paths, r.Method+" "+r.URL.Path), append(users, user)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+
+		m := NewManager(WithName("billing"), WithMetricsPush(strings.Replace(srv.URL, "http://", "http://cli:secret@", 1), "reports"))
+		m.Use(WithMetrics("test-push"))
+		m.AddFunc("f1", "", newCronFunc("f1"))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		<-m.Stop().Done()
+
+		mu.Lock()
+		defer mu.Unlock()
+		So(paths, ShouldHaveLength, 2)
+		So(paths[0], ShouldStartWith, "PUT /metrics/job/reports/")
+		So(paths[0], ShouldContainSubstring, "/manager/billing")
+		So(paths[0], ShouldContainSubstring, "/instance/")
+		So(users, ShouldResemble, []string{"cli", "cli"})
+	})
+
+	Convey("Test push failures are logged", t, func() {
+		lg := &testLogger{}
+		m := NewManager(WithManagerLogger(lg), WithMetricsPush("http://127.0.0.1:1", "reports"))
+		m.AddFunc("f1", "", newCronFunc("f1"))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		m.flushMetrics(t.Context())
+		msg, _ := lg.last()
+		So(msg, ShouldEqual, "cron metrics push failed")
+	})
+	Convey("Test slow pushgateway doesn't block runs", t, func() {
+		release := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+
+		m := NewManager(WithMetricsPush(srv.URL, "reports"))
+		m.AddFunc("f1", "", newCronFunc("f1"))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		done := make(chan error, 1)
+		go func() { done <- m.ManualRun(t.Context(), "f1") }()
+		select {
+		case err := <-done:
+			So(err, ShouldBeNil)
+		case <-time.After(time.Second):
+			So("timeout", ShouldBeEmpty)
+		}
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+
+		close(release)
+		<-m.Stop().Done()
+	})
+}
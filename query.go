@@ -0,0 +1,132 @@
+package cron
+
+import (
+	"cmp"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// stateQuery is sorting and filtering of Handler states: ?sort=next|name|duration|state&order=asc|desc,
+// ?filter=running|failed|maintenance|disabled and ?q=<name substring>. Invalid values are ignored.
+type stateQuery struct {
+	Sort   string
+	Order  string
+	Filter string
+	Q      string
+}
+
+// newStateQuery returns query from url values without invalid values.
+func newStateQuery(v url.Values) stateQuery {
+	sq := stateQuery{Q: strings.TrimSpace(v.Get("q"))}
+	if s := v.Get("sort"); slices.Contains([]string{"next", "name", "duration", "state"}, s) {
+		sq.Sort = s
+		if v.Get("order") == "desc" {
+			sq.Order = "desc"
+		}
+	}
+	if f := v.Get("filter"); slices.Contains([]string{"running", "failed", "maintenance", "disabled"}, f) {
+		sq.Filter = f
+	}
+
+	return sq
+}
+
+// apply returns filtered and sorted states.
+func (sq stateQuery) apply(states States) States {
+	q := strings.ToLower(sq.Q)
+	res := slices.DeleteFunc(slices.Clone(states), func(s State) bool {
+		return !sq.match(s) || (q != "" && !strings.Contains(strings.ToLower(s.Name), q))
+	})
+
+	var cmpFn func(a, b State) int
+	switch sq.Sort {
+	case "next":
+		// not scheduled jobs are last
+		cmpFn = func(a, b State) int {
+			if a.NextRun.IsZero() || b.NextRun.IsZero() {
+				return cmp.Compare(boolInt(a.NextRun.IsZero()), boolInt(b.NextRun.IsZero()))
+			}
+			return a.NextRun.Compare(b.NextRun)
+		}
+	case "name":
+		cmpFn = func(a, b State) int { return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)) }
+	case "duration":
+		cmpFn = func(a, b State) int { return cmp.Compare(a.LastDuration, b.LastDuration) }
+	case "state":
+		cmpFn = func(a, b State) int { return cmp.Compare(a.LastState, b.LastState) }
+	default:
+		return res
+	}
+
+	slices.SortStableFunc(res, func(a, b State) int {
+		if sq.Order == "desc" {
+			return cmpFn(b, a)
+		}
+		return cmpFn(a, b)
+	})
+
+	return res
+}
+
+// match checks state with filter.
+func (sq stateQuery) match(s State) bool {
+	st := cronState(s.LastState)
+	switch sq.Filter {
+	case "running":
+		return st.isActive() || st == stateWaiting || st == stateQueued
+	case "failed":
+		return s.LastErr != nil
+	case "maintenance":
+		return s.IsMaintenance
+	case "disabled":
+		return s.Disabled || st == stateDisabled || st == stateUnscheduled
+	}
+
+	return true
+}
+
+// SortURL returns query for column header link: ascending sort by column or reversed order if already sorted.
+func (sq stateQuery) SortURL(sort string) string {
+	order := ""
+	if sq.Sort == sort && sq.Order != "desc" {
+		order = "desc"
+	}
+
+	return sq.url(sort, order, sq.Filter)
+}
+
+// FilterURL returns query with filter, current sort and name search are kept.
+func (sq stateQuery) FilterURL(filter string) string {
+	return sq.url(sq.Sort, sq.Order, filter)
+}
+
+// SortMark returns arrow for sorted column header.
+func (sq stateQuery) SortMark(sort string) string {
+	switch {
+	case sq.Sort != sort:
+		return ""
+	case sq.Order == "desc":
+		return " ↓"
+	default:
+		return " ↑"
+	}
+}
+
+func (sq stateQuery) url(sort, order, filter string) string {
+	v := url.Values{}
+	for k, val := range map[string]string{"sort": sort, "order": order, "filter": filter, "q": sq.Q} {
+		if val != "" {
+			v.Set(k, val)
+		}
+	}
+
+	return "?" + v.Encode()
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
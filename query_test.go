@@ -0,0 +1,63 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHandler_SortFilter(t *testing.T) {
+	Convey("Test sorting and filtering of handler states", t, func() {
+		m := NewManager()
+		m.AddFunc("b-report", "@every 2m", newCronFunc("b"))
+		m.AddFunc("a-import", "@every 1m", func(context.Context) error { return errors.New("failed") })
+		m.AddMaintenanceFunc("c-vacuum", "@every 3m", newCronFunc("c"))
+		m.AddFunc("d-export", "disabled", newCronFunc("d"))
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+		So(m.ManualRun(t.Context(), "a-import"), ShouldNotBeNil)
+
+		names := func(url string) []string {
+			r := httptest.NewRequest(http.MethodGet, url, nil)
+			r.Header.Set("Accept", "application/json")
+			w := httptest.NewRecorder()
+			m.Handler(w, r)
+
+			var states []State
+			So(json.NewDecoder(w.Body).Decode(&states), ShouldBeNil)
+			var res []string
+			for _, s := range states {
+				res = append(res, s.Name)
+			}
+			return res
+		}
+
+		So(names("/"), ShouldResemble, []string{"b-report", "a-import", "c-vacuum", "d-export"})
+		So(names("/?sort=name"), ShouldResemble, []string{"a-import", "b-report", "c-vacuum", "d-export"})
+		So(names("/?sort=name&order=desc"), ShouldResemble, []string{"d-export", "c-vacuum", "b-report", "a-import"})
+		So(names("/?sort=next"), ShouldResemble, []string{"a-import", "b-report", "c-vacuum", "d-export"})
+		So(names("/?filter=failed"), ShouldResemble, []string{"a-import"})
+		So(names("/?filter=maintenance"), ShouldResemble, []string{"c-vacuum"})
+		So(names("/?filter=disabled"), ShouldResemble, []string{"d-export"})
+		So(names("/?q=EXP"), ShouldResemble, []string{"d-export"})
+		So(names("/?sort=size&filter=unknown"), ShouldResemble, []string{"b-report", "a-import", "c-vacuum", "d-export"})
+
+		Convey("Test html links", func() {
+			r := httptest.NewRequest(http.MethodGet, "/?sort=name&filter=failed", nil)
+			r.Header.Set("Accept", "text/html")
+			w := httptest.NewRecorder()
+			m.Handler(w, r)
+
+			body := w.Body.String()
+			So(body, ShouldContainSubstring, `<a href="?filter=failed&amp;order=desc&amp;sort=name" class="action-link">Name ↑</a>`)
+			So(body, ShouldContainSubstring, `<a href="?filter=maintenance&amp;sort=name" class="action-link">maintenance</a>`)
+			So(body, ShouldContainSubstring, `<b>failed</b>`)
+			So(body, ShouldNotContainSubstring, `value="b-report"`)
+		})
+	})
+}
@@ -0,0 +1,101 @@
+package cron
+
+import (
+	"context"
+)
+
+// ResourceOptions is options for WithResourceOptions.
+type ResourceOptions struct {
+	// Slots is a number of concurrent runs of jobs sharing the resource. Default is 1.
+	Slots int
+
+	// Skip skips the run if all slots are in use instead of waiting for a free slot.
+	Skip bool
+}
+
+const resourcesKey contextKey = "resources"
+
+// resource is a named semaphore shared by WithResource middleware.
+type resource struct {
+	name     string
+	slots    chan struct{}
+	mismatch bool // other slots value was requested, it is logged once
+}
+
+func newResource(name string, slots int) *resource {
+	return &resource{name: name, slots: make(chan struct{}, max(slots, 1))}
+}
+
+// resource returns manager resource by name. It is created with given slots on first use,
+// other slots values are ignored and logged.
+func (cm *Manager) resource(ctx context.Context, name string, slots int) *resource {
+	cm.muResources.Lock()
+	defer cm.muResources.Unlock()
+
+	r, ok := cm.resources[name]
+	switch {
+	case !ok:
+		if cm.resources == nil {
+			cm.resources = make(map[string]*resource)
+		}
+		r = newResource(name, slots)
+		cm.resources[name] = r
+	case cap(r.slots) != max(slots, 1) && !r.mismatch:
+		r.mismatch = true
+		if cm.logger != nil {
+			cm.logger.Error(ctx, "cron resource slots mismatch, first value is used", "resource", name, "slots", cap(r.slots), "ignored", slots)
+		}
+	}
+
+	return r
+}
+
+// WithResource limits concurrent runs of jobs sharing resource name to slots, e.g. jobs using reporting DB.
+// Jobs without the middleware or with other resource names are not affected. Job is in waiting state while it
+// waits for a free slot, waiting time is excluded from job duration. Resources are shared by name in the Manager,
+// slots are set on first use and other values are logged via WithManagerLogger.
+func WithResource(name string, slots int) MiddlewareFunc {
+	return WithResourceOptions(name, ResourceOptions{Slots: slots})
+}
+
+// WithResourceOptions is WithResource with options, e.g. to skip runs if the resource is busy.
+// If WithMetrics is added before, resource usage is tracked in app_cron_resource_in_use and
+// app_cron_resource_capacity metrics.
+func WithResourceOptions(name string, opts ResourceOptions) MiddlewareFunc {
+	own := newResource(name, opts.Slots) // used outside of Manager
+
+	return func(next Func) Func {
+		return func(ctx context.Context) error {
+			r := own
+			if fn, ok := ctx.Value(resourcesKey).(func(context.Context, string, int) *resource); ok {
+				r = fn(ctx, name, opts.Slots)
+			}
+			app, withMetrics := metricsAppFromContext(ctx)
+
+			select {
+			case r.slots <- struct{}{}:
+			default:
+				if opts.Skip {
+					return newSkipError(SkipLimit, "resource %s is busy: %d/%d slots in use", r.name, len(r.slots), cap(r.slots))
+				}
+
+				setState(ctx, stateWaiting)
+				select {
+				case r.slots <- struct{}{}:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+				setState(ctx, stateRunning)
+			}
+
+			if withMetrics {
+				metricResourceCapacity().WithLabelValues(app, r.name).Set(float64(cap(r.slots)))
+				metricResourceInUse().WithLabelValues(app, r.name).Inc()
+				defer metricResourceInUse().WithLabelValues(app, r.name).Dec()
+			}
+			defer func() { <-r.slots }()
+
+			return next(ctx)
+		}
+	}
+}
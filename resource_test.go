@@ -0,0 +1,70 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithResource(t *testing.T) {
+	Convey("Test jobs compete for resource slots", t, func() {
+		clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+		m := NewManager(WithClock(clock))
+
+		release := make(chan struct{})
+		block := func(context.Context) error {
+			<-release
+			return nil
+		}
+		m.AddFunc("f1", "", block, JobMiddleware(WithResource("test-db", 1)))
+		m.AddFunc("f2", "", newCronFunc("f2"), JobMiddleware(WithResource("test-db", 1)))
+		m.AddFunc("f3", "", newCronFunc("f3"), JobMiddleware(WithResourceOptions("test-db", ResourceOptions{Skip: true})))
+		m.AddFunc("f4", "", newCronFunc("f4"), JobMiddleware(WithResource("test-mail", 1)))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		wait := watchStates(m)
+		done1 := manualRun(t.Context(), m, "f1")
+		wait("f1", "running")
+		done2 := manualRun(t.Context(), m, "f2")
+		wait("f2", "waiting")
+
+		So(m.State()[1].LastState, ShouldEqual, "waiting")
+		So(errors.Is(m.ManualRun(t.Context(), "f3"), ErrSkipped), ShouldBeTrue)
+		So(m.State()[2].SkipReason, ShouldEqual, "resource test-db is busy: 1/1 slots in use")
+		So(m.ManualRun(t.Context(), "f4"), ShouldBeNil)
+
+		// waiting time is excluded from duration
+		clock.Add(time.Minute)
+		close(release)
+		So(<-done1, ShouldBeNil)
+		So(<-done2, ShouldBeNil)
+		st := m.State()
+		So(st[0].LastDuration, ShouldEqual, time.Minute)
+		So(st[1].LastState, ShouldEqual, "idle")
+		So(st[1].LastDuration, ShouldEqual, 0)
+	})
+	Convey("Test resources are scoped to manager", t, func() {
+		lg := &testLogger{}
+		m := NewManager(WithManagerLogger(lg))
+		m.AddFunc("f1", "", newCronFunc("f1"), JobMiddleware(WithResource("test-db", 1)))
+		m.AddFunc("f2", "", newCronFunc("f2"), JobMiddleware(WithResource("test-db", 2)))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		So(m.ManualRun(t.Context(), "f2"), ShouldBeNil)
+		msg, args := lg.last()
+		So(msg, ShouldEqual, "cron resource slots mismatch, first value is used")
+		So(args, ShouldResemble, []any{"resource", "test-db", "slots", 1, "ignored", 2})
+
+		// other manager has own resource with the same name
+		m2 := NewManager()
+		m2.AddFunc("f1", "", newCronFunc("f1"), JobMiddleware(WithResource("test-db", 2)))
+		So(m2.Run(t.Context()), ShouldBeNil)
+		So(m2.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		So(m2.resources["test-db"].slots, ShouldHaveLength, 0)
+		So(cap(m2.resources["test-db"].slots), ShouldEqual, 2)
+	})
+}
@@ -0,0 +1,81 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	errorTransient = "transient"
+	errorPermanent = "permanent"
+)
+
+// classifiedError is an error marked as transient or permanent, see Transient and Permanent.
+type classifiedError struct {
+	err   error
+	class string
+}
+
+func (e classifiedError) Error() string { return e.err.Error() }
+func (e classifiedError) Unwrap() error { return e.err }
+
+// Transient marks err as transient failure, e.g. timeout or deadlock. WithRetry retries only such errors.
+func Transient(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return classifiedError{err: err, class: errorTransient}
+}
+
+// Permanent marks err as permanent failure, e.g. bad config.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return classifiedError{err: err, class: errorPermanent}
+}
+
+// IsTransient reports whether err is marked as Transient.
+func IsTransient(err error) bool {
+	return errorClass(err) == errorTransient
+}
+
+// errorClass returns transient, permanent or empty string for unclassified error. Outer mark wins.
+func errorClass(err error) string {
+	var ce classifiedError
+	if errors.As(err, &ce) {
+		return ce.class
+	}
+
+	return ""
+}
+
+// WithRetry retries failed run up to attempts times with delay between attempts if job returned Transient error.
+// Other errors are returned right away. Job is in waiting state during the delay.
+func WithRetry(attempts int, delay time.Duration) MiddlewareFunc {
+	return func(next Func) Func {
+		return func(ctx context.Context) error {
+			err := next(ctx)
+			for range attempts {
+				if !IsTransient(err) {
+					return err
+				}
+
+				setState(ctx, stateWaiting)
+				select {
+				case <-ctx.Done():
+					return err
+				case <-clockAfter(clockFromContext(ctx), delay):
+				}
+
+				setState(ctx, stateRunning)
+				err = next(ctx)
+			}
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTransient(t *testing.T) {
+	Convey("Test error classification", t, func() {
+		errDeadlock := errors.New("deadlock")
+		So(IsTransient(Transient(errDeadlock)), ShouldBeTrue)
+		So(IsTransient(fmt.Errorf("sync: %w", Transient(errDeadlock))), ShouldBeTrue)
+		So(IsTransient(Permanent(Transient(errDeadlock))), ShouldBeFalse)
+		So(IsTransient(errDeadlock), ShouldBeFalse)
+		So(errors.Is(Transient(errDeadlock), errDeadlock), ShouldBeTrue)
+		So(Transient(nil), ShouldBeNil)
+		So(Permanent(nil), ShouldBeNil)
+	})
+}
+
+func TestWithRetry(t *testing.T) {
+	Convey("Test retry of transient errors", t, func() {
+		clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC), after: make(chan time.Duration)}
+		go func() {
+			for d := range clock.after {
+				clock.Add(d)
+			}
+		}()
+		defer close(clock.after)
+
+		m := NewManager(WithClock(clock))
+		m.Use(WithMetrics("test-retry"), WithRetry(2, time.Minute))
+		permanent := metricDelta("app_cron_evaluated_total", map[string]string{"app": "test-retry", "cron": "f2", "state": "error_permanent"})
+
+		var runs int
+		errs := []error{Transient(errors.New("timeout")), Transient(errors.New("timeout")), nil}
+		m.AddFunc("f1", "", func(context.Context) error {
+			runs++
+			return errs[runs-1]
+		})
+		m.AddFunc("f2", "", func(context.Context) error {
+			runs++
+			return Permanent(errors.New("bad config"))
+		})
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		So(m.ManualRun(t.Context(), "f1"), ShouldBeNil)
+		So(runs, ShouldEqual, 3)
+
+		runs = 0
+		So(m.ManualRun(t.Context(), "f2"), ShouldNotBeNil)
+		So(runs, ShouldEqual, 1)
+		So(permanent(), ShouldEqual, 1)
+	})
+}
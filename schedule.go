@@ -0,0 +1,197 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// maxOccurrences limits occurrences per job.
+	maxOccurrences = 10000
+
+	// detailNextRuns is a number of next runs on job details page.
+	detailNextRuns = 10
+)
+
+var ErrInvalidHash = errors.New("invalid H token")
+
+// Occurrence is a planned job activation.
+type Occurrence struct {
+	JobName       string
+	Time          time.Time
+	IsMaintenance bool
+}
+
+// hashBounds are H ranges for standard spec fields: minute, hour, day of month, month, day of week.
+// Day of month is limited by 28 to fire every month.
+var hashBounds = [5][2]int{{0, 59}, {0, 23}, {1, 28}, {1, 12}, {0, 6}}
+
+// WithScheduleParser sets custom parser for job schedules, e.g. for own DSL or cron.NewParser with seconds.
+// Comments and H tokens are resolved before parsing. Default parser is cron.ParseStandard.
+func WithScheduleParser(p cron.ScheduleParser) Option {
+	return func(cm *Manager) {
+		cm.parser = p
+	}
+}
+
+// parse strips comment, resolves H tokens in schedule and parses it. It returns parsed schedule and resolved spec.
+func (cm *Manager) parse(name string, schedule Schedule) (cron.Schedule, string, error) {
+	key := name
+	if cm.name != "" {
+		key = cm.name + "/" + name
+	}
+
+	spec, err := resolveHash(schedule.withoutComment(), key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if at, ok, err := parseAt(spec); ok {
+		return at, spec, err
+	}
+
+	parse := cron.ParseStandard
+	switch {
+	case cm.parser != nil:
+		parse = cm.parser.Parse
+	case cm.extendedSyntax:
+		parse = parseExtended
+	}
+
+	sch, err := parse(spec)
+	return sch, spec, err
+}
+
+// resolveHash replaces Jenkins-style H tokens in spec with values derived from key hash:
+// H, H(2-5), H/15, H(0-29)/10. Result is stable for the same key and spread across different keys.
+func resolveHash(spec, key string) (string, error) {
+	fields := strings.Fields(spec)
+	if !strings.Contains(spec, "H") || len(fields) != len(hashBounds) {
+		return spec, nil
+	}
+
+	for i, field := range fields {
+		items := strings.Split(field, ",")
+		for j, item := range items {
+			if !strings.HasPrefix(item, "H") {
+				continue
+			}
+
+			v, err := resolveHashItem(item, fmt.Sprintf("%s/%d", key, i), hashBounds[i][0], hashBounds[i][1])
+			if err != nil {
+				return "", fmt.Errorf("%w %q: %w", ErrInvalidHash, item, err)
+			}
+			items[j] = v
+		}
+		fields[i] = strings.Join(items, ",")
+	}
+
+	return strings.Join(fields, " "), nil
+}
+
+// resolveHashItem resolves one H item within [lo, hi] bounds.
+func resolveHashItem(item, key string, lo, hi int) (string, error) {
+	rest := strings.TrimPrefix(item, "H")
+
+	// parse optional range H(a-b)
+	if strings.HasPrefix(rest, "(") {
+		end := strings.Index(rest, ")")
+		if end < 0 {
+			return "", errors.New("unclosed range")
+		}
+
+		a, b, ok := strings.Cut(rest[1:end], "-")
+		if !ok {
+			return "", errors.New("range must be a-b")
+		}
+
+		from, err := strconv.Atoi(a)
+		if err != nil {
+			return "", fmt.Errorf("invalid range start: %w", err)
+		}
+		to, err := strconv.Atoi(b)
+		if err != nil {
+			return "", fmt.Errorf("invalid range end: %w", err)
+		}
+		if from > to || from < lo || to > hi {
+			return "", fmt.Errorf("range must be within %d-%d", lo, hi)
+		}
+
+		lo, hi, rest = from, to, rest[end+1:]
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	sum := int(h.Sum32() & 0x7fffffff)
+
+	// plain H or H(a-b)
+	if rest == "" {
+		return strconv.Itoa(lo + sum%(hi-lo+1)), nil
+	}
+
+	// H/n or H(a-b)/n
+	step, ok := strings.CutPrefix(rest, "/")
+	if !ok {
+		return "", errors.New("unexpected suffix")
+	}
+
+	n, err := strconv.Atoi(step)
+	if err != nil || n <= 0 {
+		return "", errors.New("invalid step")
+	}
+
+	return fmt.Sprintf("%d-%d/%d", lo+sum%min(n, hi-lo+1), hi, n), nil
+}
+
+// Occurrences returns planned activations of all active jobs in [from, to) sorted by time.
+// Nothing is executed. @every schedules are anchored at Run time.
+func (cm *Manager) Occurrences(from, to time.Time) []Occurrence {
+	var rr []Occurrence
+	for _, j := range cm.jobs {
+		rr = append(rr, cm.jobOccurrences(j, from, to, maxOccurrences)...)
+	}
+
+	slices.SortStableFunc(rr, func(a, b Occurrence) int { return a.Time.Compare(b.Time) })
+	return rr
+}
+
+// jobOccurrences returns up to limit planned activations of the job in [from, to).
+func (cm *Manager) jobOccurrences(j *job, from, to time.Time, limit int) []Occurrence {
+	sch, err := cm.Schedule(j.name)
+	if err != nil || sch == nil {
+		return nil
+	}
+
+	n, t := 0, sch.Next(from.Add(-time.Second))
+	if d, ok := sch.(cron.ConstantDelaySchedule); ok {
+		t = anchorDelay(d.Delay, cm.startedAt, from)
+	}
+
+	var rr []Occurrence
+	for ; !t.IsZero() && t.Before(to) && n < limit; t, n = sch.Next(t), n+1 {
+		rr = append(rr, Occurrence{JobName: j.name, Time: t, IsMaintenance: j.isMaintenance})
+	}
+
+	return rr
+}
+
+// anchorDelay returns first activation at or after from for interval schedule started at start.
+// Schedule is anchored at from if it is not started yet.
+func anchorDelay(delay time.Duration, start, from time.Time) time.Time {
+	switch {
+	case start.IsZero():
+		return from.Add(delay)
+	case start.After(from):
+		return start.Add(delay)
+	}
+
+	n := max((from.Sub(start)+delay-1)/delay, 1)
+	return start.Add(n * delay)
+}
@@ -0,0 +1,136 @@
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestResolveHash(t *testing.T) {
+	Convey("Test H schedule resolving", t, func() {
+		Convey("Test stable and spread", func() {
+			s1, err := resolveHash("H H(2-5) * * *", "f1")
+			So(err, ShouldBeNil)
+			s2, _ := resolveHash("H H(2-5) * * *", "f1")
+			So(s1, ShouldEqual, s2)
+
+			s3, _ := resolveHash("H H(2-5) * * *", "f2")
+			So(s3, ShouldNotEqual, s1)
+		})
+
+		Convey("Test steps and lists", func() {
+			spec, err := resolveHash("H/15 H(0-11),H(12-23) * * 1-5", "f1")
+			So(err, ShouldBeNil)
+			So(spec, ShouldNotContainSubstring, "H")
+
+			m := NewManager()
+			m.AddFunc("f1", "H/15 H(0-11),H(12-23) * * 1-5", newCronFunc("f1"))
+			_, err = m.validateJobs()
+			So(err, ShouldBeNil)
+			So(m.State()[0].Spec, ShouldEqual, spec)
+		})
+
+		Convey("Test not standard spec", func() {
+			spec, err := resolveHash("@hourly", "f1")
+			So(err, ShouldBeNil)
+			So(spec, ShouldEqual, "@hourly")
+		})
+
+		Convey("Test invalid H", func() {
+			for _, spec := range []string{"H(5-2) * * * *", "H(a-b) * * * *", "H(0-99) * * * *", "H/0 * * * *", "H(1-2 * * * *", "Hx * * * *"} {
+				_, err := resolveHash(spec, "f1")
+				So(errors.Is(err, ErrInvalidHash), ShouldBeTrue)
+			}
+		})
+	})
+}
+
+func TestSchedule_Comment(t *testing.T) {
+	Convey("Test schedule comments", t, func() {
+		Convey("Test comment stripping", func() {
+			for schedule, spec := range map[Schedule]string{
+				"0 3 * * * # nightly cleanup": "0 3 * * *",
+				"0 3 * * *\t#nightly":         "0 3 * * *",
+				"0 3 * * * #":                 "0 3 * * *",
+				"0 3 * * *":                   "0 3 * * *",
+				"0 3 * * 5#3":                 "0 3 * * 5#3",
+				"# 0 3 * * *":                 "",
+			} {
+				So(schedule.withoutComment(), ShouldEqual, spec)
+			}
+
+			So(Schedule("disabled # until migration").IsActive(), ShouldBeFalse)
+			So(Schedule("# 0 3 * * *").IsActive(), ShouldBeFalse)
+		})
+
+		Convey("Test raw schedule is preserved", func() {
+			m := NewManager()
+			m.AddFunc("f1", "0 3 * * * # nightly cleanup", newCronFunc("f1"))
+			_, err := m.validateJobs()
+			So(err, ShouldBeNil)
+
+			st := m.State()[0]
+			So(st.Schedule, ShouldEqual, "0 3 * * * # nightly cleanup")
+			So(st.Spec, ShouldEqual, "0 3 * * *")
+		})
+	})
+}
+
+func TestManager_Occurrences(t *testing.T) {
+	Convey("Test occurrences", t, func() {
+		start := time.Date(2025, 1, 3, 17, 0, 0, 0, time.Local)
+		clock := &testClock{now: start}
+		m := NewManager(WithClock(clock), WithManualTicker())
+		m.AddFunc("nightly", "0 3 * * *", newCronFunc("nightly"))
+		m.AddMaintenanceFunc("vacuum", "0 0 * * 0", newCronFunc("vacuum"))
+		m.AddFunc("every", "@every 20h", newCronFunc("every"))
+		m.AddFunc("disabled", "", newCronFunc("disabled"))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		from := time.Date(2025, 1, 3, 18, 0, 0, 0, time.Local)
+		rr := m.Occurrences(from, from.Add(63*time.Hour))
+		So(rr, ShouldResemble, []Occurrence{
+			{JobName: "nightly", Time: time.Date(2025, 1, 4, 3, 0, 0, 0, time.Local)},
+			{JobName: "every", Time: time.Date(2025, 1, 4, 13, 0, 0, 0, time.Local)},
+			{JobName: "vacuum", Time: time.Date(2025, 1, 5, 0, 0, 0, 0, time.Local), IsMaintenance: true},
+			{JobName: "nightly", Time: time.Date(2025, 1, 5, 3, 0, 0, 0, time.Local)},
+			{JobName: "every", Time: time.Date(2025, 1, 5, 9, 0, 0, 0, time.Local)},
+			{JobName: "nightly", Time: time.Date(2025, 1, 6, 3, 0, 0, 0, time.Local)},
+			{JobName: "every", Time: time.Date(2025, 1, 6, 5, 0, 0, 0, time.Local)},
+		})
+	})
+}
+
+type testParser map[string]string
+
+func (p testParser) Parse(spec string) (cron.Schedule, error) {
+	if s, ok := p[spec]; ok {
+		return cron.ParseStandard(s)
+	}
+	return nil, errors.New("unknown schedule")
+}
+
+func TestWithScheduleParser(t *testing.T) {
+	Convey("Test custom schedule parser", t, func() {
+		p := testParser{"nightly": "0 3 * * *"}
+
+		m := NewManager(WithScheduleParser(p), WithManualTicker())
+		m.AddFunc("f1", "nightly # cleanup", newCronFunc("f1"))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		sch, err := m.Schedule("f1")
+		So(err, ShouldBeNil)
+		from := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		So(sch.Next(from), ShouldEqual, time.Date(2025, 1, 2, 3, 0, 0, 0, time.UTC))
+
+		oo := m.Occurrences(from, from.Add(48*time.Hour))
+		So(oo, ShouldHaveLength, 2)
+
+		m = NewManager(WithScheduleParser(p))
+		m.AddFunc("f1", "0 3 * * *", newCronFunc("f1"))
+		So(m.Run(t.Context()), ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,114 @@
+package cron
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+const serialKey contextKey = "serial"
+
+// SerialTicket describes job run position in WithSerial queue.
+type SerialTicket struct {
+	Position int           // jobs ahead in the queue when the run arrived, 0 if it started immediately
+	Wait     time.Duration // time spent in the queue
+}
+
+// NewSerialContext creates new context with WithSerial queue ticket.
+func NewSerialContext(ctx context.Context, t SerialTicket) context.Context {
+	return context.WithValue(ctx, serialKey, t)
+}
+
+// SerialFromContext returns WithSerial queue ticket of the run.
+func SerialFromContext(ctx context.Context) (SerialTicket, bool) {
+	t, ok := ctx.Value(serialKey).(SerialTicket)
+	return t, ok
+}
+
+// serialQueue is a FIFO mutex: waiting runs acquire it strictly in arrival order.
+type serialQueue struct {
+	mu    sync.Mutex
+	busy  bool
+	queue []chan struct{}
+}
+
+// lock acquires the queue and returns the number of runs ahead on arrival.
+// It returns ctx error if ctx is done while waiting.
+func (q *serialQueue) lock(ctx context.Context, queued func()) (int, error) {
+	q.mu.Lock()
+	if !q.busy {
+		q.busy = true
+		q.mu.Unlock()
+		return 0, nil
+	}
+
+	ready := make(chan struct{})
+	q.queue = append(q.queue, ready)
+	pos := len(q.queue)
+	q.mu.Unlock()
+	queued()
+
+	select {
+	case <-ready:
+		return pos, nil
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// queue was handed over concurrently with ctx cancellation
+	i := slices.Index(q.queue, ready)
+	if i < 0 {
+		q.unlockLocked()
+		return pos, ctx.Err()
+	}
+
+	q.queue = slices.Delete(q.queue, i, i+1)
+	return pos, ctx.Err()
+}
+
+// unlock hands the queue over to the next waiting run.
+func (q *serialQueue) unlock() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.unlockLocked()
+}
+
+func (q *serialQueue) unlockLocked() {
+	if len(q.queue) == 0 {
+		q.busy = false
+		return
+	}
+
+	close(q.queue[0])
+	q.queue = q.queue[1:]
+}
+
+// WithSerial runs jobs strictly one at a time in the order their triggers fired.
+// Job is in queued state while it waits for its turn. Queue position and wait time are available
+// via SerialFromContext for middleware added after WithSerial and for the job itself.
+func WithSerial() MiddlewareFunc {
+	q := &serialQueue{}
+
+	return func(next Func) Func {
+		return func(ctx context.Context) error {
+			clock := clockFromContext(ctx)
+			start := clock.Now()
+
+			pos, err := q.lock(ctx, func() { setState(ctx, stateQueued) })
+			if err != nil {
+				return err
+			}
+			defer q.unlock()
+
+			if pos > 0 {
+				setState(ctx, stateRunning)
+			}
+
+			return next(NewSerialContext(ctx, SerialTicket{Position: pos, Wait: clock.Since(start)}))
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package cron
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithSerial(t *testing.T) {
+	Convey("Test serial queue runs jobs in arrival order", t, func() {
+		m := NewManager()
+		m.Use(WithSerial())
+
+		var (
+			mu      sync.Mutex
+			order   []string
+			tickets = map[string]SerialTicket{}
+		)
+		release := make(chan struct{})
+		fn := func(name string) Func {
+			return func(ctx context.Context) error {
+				if name == "f1" {
+					<-release
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				order = append(order, name)
+				tickets[name], _ = SerialFromContext(ctx)
+				return nil
+			}
+		}
+		for _, name := range []string{"f1", "f2", "f3"} {
+			m.AddFunc(name, "", fn(name))
+		}
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		wait := watchStates(m)
+		var done []<-chan error
+		for _, name := range []string{"f1", "f3", "f2"} {
+			done = append(done, manualRun(t.Context(), m, name))
+			if name == "f1" {
+				wait(name, "running")
+			} else {
+				wait(name, "queued")
+			}
+		}
+
+		st := m.State()
+		So(st[0].LastState, ShouldEqual, "running")
+		So(st[1].LastState, ShouldEqual, "queued")
+		So(st[2].LastState, ShouldEqual, "queued")
+		So(m.Running(), ShouldResemble, []string{"f1", "f2", "f3"})
+
+		close(release)
+		for _, ch := range done {
+			So(<-ch, ShouldBeNil)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		So(order, ShouldResemble, []string{"f1", "f3", "f2"})
+		So(tickets["f1"].Position, ShouldEqual, 0)
+		So(tickets["f3"].Position, ShouldEqual, 1)
+		So(tickets["f2"].Position, ShouldEqual, 2)
+		So(tickets["f2"].Wait, ShouldBeGreaterThan, 0)
+		So(m.Running(), ShouldBeEmpty)
+	})
+}
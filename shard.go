@@ -0,0 +1,74 @@
+package cron
+
+import (
+	"context"
+	"hash/fnv"
+)
+
+// sharding distributes jobs across replicas, see WithSharding.
+type sharding struct {
+	self    string
+	members func() []string
+}
+
+// WithSharding runs each scheduled job only on one replica chosen by rendezvous hashing over job name.
+// Members func returns IDs of all replicas (including selfID) and is called on every run, so membership can change
+// between ticks. If members is empty, jobs run locally. Runs on other replicas are skipped with reason "not owner".
+// Manual runs are not affected.
+func WithSharding(selfID string, members func() []string) Option {
+	return func(cm *Manager) {
+		cm.sharding = &sharding{self: selfID, members: members}
+	}
+}
+
+// owner returns replica ID for job name with the highest hash score.
+func (s sharding) owner(name string) string {
+	var (
+		owner string
+		best  uint64
+	)
+
+	for _, m := range s.members() {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(m + "/" + name))
+		if score := mix64(h.Sum64()); owner == "" || score > best {
+			owner, best = m, score
+		}
+	}
+
+	if owner == "" {
+		return s.self
+	}
+
+	return owner
+}
+
+// newShardFunc wraps scheduled job fn with ownership check.
+func (cm *Manager) newShardFunc(idx int, fn Func) Func {
+	return func(ctx context.Context) error {
+		j := cm.jobs[idx]
+		owner := cm.sharding.owner(j.name)
+
+		cm.muState.Lock()
+		j.owner = owner
+		cm.muState.Unlock()
+
+		if owner != cm.sharding.self {
+			err := newSkipError(SkipReplica, "not owner, owner is %s", owner)
+			cm.skipRun(idx, err)
+			return err
+		}
+
+		return fn(ctx)
+	}
+}
+
+// mix64 is a splitmix64 finalizer: fnv hashes of similar keys differ only in a few bits.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
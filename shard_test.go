@@ -0,0 +1,61 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithSharding(t *testing.T) {
+	Convey("Test sharding", t, func() {
+		members := []string{"r1", "r2", "r3", "r4"}
+
+		Convey("Test owner is stable and spread", func() {
+			s := sharding{self: "r1", members: func() []string { return members }}
+			owners := map[string]int{}
+			for i := range 100 {
+				name := fmt.Sprintf("job%d", i)
+				So(s.owner(name), ShouldEqual, s.owner(name))
+				owners[s.owner(name)]++
+			}
+			So(owners, ShouldHaveLength, 4)
+
+			// new member takes only its own jobs
+			before := s.owner("job1")
+			s.members = func() []string { return []string{"r1", "r2", "r3", "r4", "r5"} }
+			if after := s.owner("job1"); after != "r5" {
+				So(after, ShouldEqual, before)
+			}
+
+			s.members = func() []string { return nil }
+			So(s.owner("job1"), ShouldEqual, "r1")
+		})
+
+		Convey("Test job runs only on owner", func() {
+			clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 30, 0, time.UTC)}
+
+			var runs int
+			for _, self := range members {
+				m := NewManager(WithClock(clock), WithManualTicker(), WithSharding(self, func() []string { return members }))
+				m.AddFunc("f1", "* * * * *", func(context.Context) error {
+					runs++
+					return nil
+				})
+				So(m.Run(t.Context()), ShouldBeNil)
+
+				err := m.Tick(t.Context(), clock.Now().Add(time.Minute))
+				st := m.State()[0]
+				So(st.Owner, ShouldBeIn, members)
+				if st.Owner != self {
+					So(errors.Is(err, ErrSkipped), ShouldBeTrue)
+					So(st.SkipReason, ShouldEqual, "not owner, owner is "+st.Owner)
+				}
+			}
+			So(runs, ShouldEqual, 1)
+		})
+	})
+}
@@ -0,0 +1,219 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// maxMissed limits missed runs calculation for frequent schedules.
+const maxMissed = 1000
+
+// Store persists job states across restarts.
+type Store interface {
+	Save(ctx context.Context, states States) error
+	Load(ctx context.Context) (States, error)
+}
+
+// MemoryStore is an in-memory Store. It keeps states between Manager instances within one process.
+type MemoryStore struct {
+	mu     sync.Mutex
+	states States
+}
+
+// NewMemoryStore returns new in-memory Store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Save implements Store.
+func (s *MemoryStore) Save(_ context.Context, states States) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.states = slices.Clone(states)
+	return nil
+}
+
+// Load implements Store.
+func (s *MemoryStore) Load(context.Context) (States, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.states), nil
+}
+
+// FileStore is a Store that keeps states in JSON file.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileStore returns new Store for JSON file at path. File is created on first save.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Save implements Store. File is replaced atomically.
+func (s *FileStore) Save(_ context.Context, states States) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	if err = os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.path)
+}
+
+// Load implements Store. It returns no states if file doesn't exist.
+func (s *FileStore) Load(context.Context) (States, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var states States
+	if err = json.Unmarshal(b, &states); err != nil {
+		return nil, fmt.Errorf("invalid store file %s: %w", s.path, err)
+	}
+
+	return states, nil
+}
+
+// CatchUpPolicy defines what to do with runs missed during downtime.
+type CatchUpPolicy int
+
+const (
+	CatchUpNone    CatchUpPolicy = iota // only count missed runs
+	CatchUpRunOnce                      // run job once on start
+	CatchUpRunAll                       // run job on start as many times as it was missed
+)
+
+// WithStore saves job states to s after every run and restores them on Run: last run, error, duration, failures
+// and app label of WithMetrics.
+// Missed runs are calculated from restored last runs, see CatchUp.
+func WithStore(s Store) Option {
+	return func(cm *Manager) {
+		cm.store = s
+	}
+}
+
+// CatchUp sets policy for runs missed during downtime. It requires WithStore.
+func CatchUp(p CatchUpPolicy) JobOption {
+	return func(j *job) {
+		j.catchUp = p
+	}
+}
+
+// restoreState loads states from store and calculates missed runs.
+func (cm *Manager) restoreState(ctx context.Context) error {
+	states, err := cm.store.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	index := make(map[string]State, len(states))
+	for _, st := range states {
+		index[strings.ToLower(st.Name)] = st
+	}
+
+	cm.muState.Lock()
+	defer cm.muState.Unlock()
+
+	now := cm.clock.Now()
+	for idx := range cm.jobs {
+		j := cm.jobs[idx]
+		st, ok := index[strings.ToLower(j.name)]
+		if !ok || !j.schedule.IsActive() {
+			continue
+		}
+
+		j.last.lastRun = st.LastRun
+		j.last.err, j.last.stack, j.last.reason, j.last.kind = st.LastErr, []byte(st.LastStack), st.SkipReason, st.SkipKind
+		j.last.duration, j.last.updatedAt, j.last.failures = st.LastDuration, st.LastUpdatedAt, st.Failures
+		j.last.missed = countMissed(j.sched, st.LastRun, now)
+		j.metricsApp = st.App
+		if j.last.missed > 0 {
+			metricMissed().WithLabelValues(j.metricsApp, j.name).Add(float64(j.last.missed))
+		}
+	}
+
+	return nil
+}
+
+// saveState saves current states to store if any.
+func (cm *Manager) saveState(ctx context.Context) {
+	if cm.store == nil {
+		return
+	}
+
+	// job result doesn't depend on store
+	_ = cm.store.Save(context.WithoutCancel(ctx), cm.State())
+}
+
+// catchUp runs missed jobs according to their catch up policy.
+func (cm *Manager) catchUp(ctx context.Context) {
+	for idx, j := range cm.jobs {
+		n := 0
+		switch j.catchUp {
+		case CatchUpNone:
+		case CatchUpRunOnce:
+			n = min(j.last.missed, 1)
+		case CatchUpRunAll:
+			n = j.last.missed
+		}
+
+		if n == 0 {
+			continue
+		}
+
+		if cm.pool != nil {
+			for range n {
+				cm.enqueue(ctx, idx, j.schedFn)
+			}
+			continue
+		}
+
+		cm.wgBackground.Add(1)
+		go func() {
+			defer cm.wgBackground.Done()
+			for range n {
+				_ = j.schedFn(ctx)
+			}
+		}()
+	}
+}
+
+// countMissed returns number of s activations after last and before now.
+func countMissed(s cron.Schedule, last, now time.Time) int {
+	if last.IsZero() {
+		return 0
+	}
+
+	n := 0
+	for t := s.Next(last); !t.IsZero() && t.Before(now) && n < maxMissed; t = s.Next(t) {
+		n++
+	}
+
+	return n
+}
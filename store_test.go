@@ -0,0 +1,82 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestManager_CatchUp(t *testing.T) {
+	Convey("Test missed runs catch up", t, func() {
+		lastRun := time.Now().Add(-3 * time.Hour).Truncate(time.Hour)
+		store := &MemoryStore{states: States{
+			{Name: "f1", LastRun: lastRun},
+			{Name: "f2", LastRun: lastRun},
+			{Name: "f3", LastRun: lastRun, App: "test-catchup"},
+		}}
+
+		var r1, r2, r3 atomic.Int32
+		counter := func(c *atomic.Int32) Func {
+			return func(context.Context) error {
+				c.Add(1)
+				return nil
+			}
+		}
+
+		missed := metricDelta("app_cron_missed_total", map[string]string{"app": "test-catchup", "cron": "f3"})
+		m := NewManager(WithStore(store))
+		m.AddFunc("f1", "@hourly", counter(&r1), CatchUp(CatchUpRunAll))
+		m.AddFunc("f2", "@hourly", counter(&r2), CatchUp(CatchUpRunOnce))
+		m.AddFunc("f3", "@hourly", counter(&r3))
+		So(m.Run(t.Context()), ShouldBeNil)
+		defer m.Stop()
+
+		time.Sleep(100 * time.Millisecond)
+		So(r1.Load(), ShouldEqual, 3)
+		So(r2.Load(), ShouldEqual, 1)
+		So(r3.Load(), ShouldEqual, 0)
+
+		st := m.State()
+		So(st[2].Missed, ShouldEqual, 3)
+		So(st[2].LastRun, ShouldEqual, lastRun)
+		So(st[2].App, ShouldEqual, "test-catchup")
+		So(missed(), ShouldEqual, 3)
+	})
+}
+
+func TestFileStore(t *testing.T) {
+	Convey("Test state restore from file", t, func() {
+		store := NewFileStore(filepath.Join(t.TempDir(), "cron.json"))
+		states, err := store.Load(t.Context())
+		So(err, ShouldBeNil)
+		So(states, ShouldBeEmpty)
+
+		clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 30, 0, time.UTC)}
+		newManager := func() *Manager {
+			m := NewManager(WithClock(clock), WithManualTicker(), WithStore(store))
+			m.AddFunc("f1", "* * * * *", func(context.Context) error { return errors.New("failed") })
+			So(m.Run(t.Context()), ShouldBeNil)
+			return m
+		}
+
+		m := newManager()
+		clock.Add(time.Minute)
+		So(m.Tick(t.Context(), clock.Now()), ShouldNotBeNil)
+		m.Stop()
+
+		// restart
+		clock.Add(10 * time.Second)
+		st := newManager().State()[0]
+		So(st.LastErr, ShouldNotBeNil)
+		So(st.LastErr.Error(), ShouldEqual, "failed")
+		So(st.Failures, ShouldEqual, 1)
+		So(st.LastUpdatedAt, ShouldEqual, time.Date(2025, 1, 1, 12, 1, 30, 0, time.UTC))
+		So(st.LastRun.IsZero(), ShouldBeFalse)
+		So(st.Missed, ShouldEqual, 0)
+	})
+}
@@ -0,0 +1,83 @@
+package cron
+
+import (
+	"context"
+	"time"
+)
+
+// ExpectRuntime sets expected max runtime of the job. Watchdog marks running job as stuck after d
+// without stopping it, logs it via WithManagerLogger and counts it in app_cron_stuck_total metric
+// (with app label of WithMetrics).
+func ExpectRuntime(d time.Duration) JobOption {
+	return func(j *job) {
+		j.expRuntime = d
+	}
+}
+
+// WithWatchdogInterval sets how often watchdog checks for stuck jobs. Default is 10s.
+func WithWatchdogInterval(d time.Duration) Option {
+	return func(cm *Manager) {
+		cm.watchdogInterval = d
+	}
+}
+
+// startWatchdog starts background check for stuck jobs if any job has ExpectRuntime option.
+func (cm *Manager) startWatchdog(ctx context.Context) {
+	var hasExpected bool
+	for _, j := range cm.jobs {
+		hasExpected = hasExpected || j.expRuntime > 0
+	}
+	if !hasExpected || cm.watchdogInterval <= 0 {
+		return
+	}
+
+	cm.wgBackground.Add(1)
+	go func() {
+		defer cm.wgBackground.Done()
+		t := time.NewTicker(cm.watchdogInterval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				cm.markStuck(ctx)
+			}
+		}
+	}()
+}
+
+// markStuck sets stuck state for jobs running longer than expected.
+func (cm *Manager) markStuck(ctx context.Context) {
+	type stuckJob struct {
+		idx     int
+		name    string
+		app     string
+		running time.Duration
+	}
+
+	var stuck []stuckJob
+	cm.muState.Lock()
+	now := cm.clock.Now()
+	for i := range cm.jobs {
+		j := cm.jobs[i]
+		if j.expRuntime <= 0 || !j.last.state.isActive() || j.last.state == stateStuck {
+			continue
+		}
+
+		if d := now.Sub(j.last.startedAt) - j.last.waited; d > j.expRuntime {
+			j.last.state, j.last.updatedAt = stateStuck, now
+			stuck = append(stuck, stuckJob{idx: i, name: j.name, app: j.metricsApp, running: d})
+		}
+	}
+	cm.muState.Unlock()
+
+	for _, s := range stuck {
+		metricStuck().WithLabelValues(s.app, s.name).Inc()
+		if cm.logger != nil {
+			cm.logger.Error(ctx, "cron job stuck", "job", s.name, "running", s.running)
+		}
+		cm.notifyState(s.idx)
+	}
+}
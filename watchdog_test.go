@@ -0,0 +1,38 @@
+package cron
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWatchdog(t *testing.T) {
+	Convey("Test watchdog marks stuck jobs", t, func() {
+		lg := &testLogger{}
+		m := NewManager(WithManagerLogger(lg), WithWatchdogInterval(20*time.Millisecond))
+		m.Use(WithMetrics("test-watchdog"))
+		stuck := metricDelta("app_cron_stuck_total", map[string]string{"app": "test-watchdog", "cron": "f1"})
+
+		release := make(chan struct{})
+		m.AddFunc("f1", "", func(context.Context) error {
+			<-release
+			return nil
+		}, ExpectRuntime(50*time.Millisecond))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		go func() { _ = m.ManualRun(t.Context(), "f1") }()
+		time.Sleep(150 * time.Millisecond)
+		So(m.State()[0].LastState, ShouldEqual, "stuck")
+		msg, _ := lg.last()
+		So(msg, ShouldEqual, "cron job stuck")
+		So(stuck(), ShouldEqual, 1)
+
+		close(release)
+		time.Sleep(20 * time.Millisecond)
+		st := m.State()[0]
+		So(st.LastState, ShouldEqual, "idle")
+		So(st.LastDuration, ShouldBeGreaterThan, 100*time.Millisecond)
+	})
+}
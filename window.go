@@ -0,0 +1,182 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// window is a daily wall-clock interval [from, to) in location.
+// Window with from > to spans midnight, e.g. 22:00-06:00.
+type window struct {
+	from, to int // minutes since midnight
+	loc      *time.Location
+}
+
+// OnlyBetween allows job runs only between from and to (e.g. "09:00", "18:00") in loc (time.Local if nil).
+// Schedule controls cadence, window controls eligibility: ticks outside the window are skipped.
+// Window uses wall-clock time, so during DST transitions it becomes an hour shorter or longer.
+func OnlyBetween(from, to string, loc *time.Location) JobOption {
+	return func(j *job) {
+		w, err := newWindow(from, to, loc)
+		if err != nil {
+			j.err = err
+			return
+		}
+
+		j.window = &w
+		j.middleware = append(j.middleware, w.middleware())
+	}
+}
+
+// MaintenanceWindow allows scheduled runs of maintenance jobs only between from and to in loc (time.Local if nil).
+// Maintenance jobs fired outside the window are skipped (or deferred, see WithMaintenanceDefer). Regular jobs and
+// manual runs are not affected.
+func MaintenanceWindow(from, to string, loc *time.Location) Option {
+	return func(cm *Manager) {
+		w, err := newWindow(from, to, loc)
+		if err != nil {
+			cm.err = err
+			return
+		}
+
+		cm.maintenanceWindow = &w
+	}
+}
+
+// WithMaintenanceDefer defers maintenance jobs fired outside MaintenanceWindow to the window start instead of skipping.
+func WithMaintenanceDefer() Option {
+	return func(cm *Manager) {
+		cm.maintenanceDefer = true
+	}
+}
+
+// newMaintenanceWindowFunc wraps scheduled maintenance job fn with maintenance window check.
+func (cm *Manager) newMaintenanceWindowFunc(idx int, fn Func) Func {
+	w := cm.maintenanceWindow
+
+	return func(ctx context.Context) error {
+		now := cm.clock.Now()
+		if w.contains(now) {
+			return fn(ctx)
+		}
+
+		err := newSkipError(SkipMaintenance, "outside maintenance window %s", w)
+		if opensAt := w.opensAt(now); cm.maintenanceDefer {
+			err = newSkipError(SkipMaintenance, "outside maintenance window %s, deferred to %s", w, opensAt.Format("15:04"))
+			cm.deferRun(ctx, idx, opensAt.Sub(now), fn)
+		}
+
+		cm.skipRun(idx, err)
+		return err
+	}
+}
+
+// deferRun runs fn after d by manager clock. Only one deferred run per job is planned,
+// it is dropped on Stop or Reset.
+func (cm *Manager) deferRun(ctx context.Context, idx int, d time.Duration, fn Func) {
+	cm.muState.Lock()
+	defer cm.muState.Unlock()
+
+	j := cm.jobs[idx]
+	if j.deferred || cm.background == nil {
+		return
+	}
+
+	j.deferred = true
+	bg := cm.background
+	cm.wgBackground.Add(1)
+	go func() {
+		defer cm.wgBackground.Done()
+
+		var run bool
+		select {
+		case <-bg.Done():
+		case <-ctx.Done():
+		case <-clockAfter(cm.clock, d):
+			run = true
+		}
+
+		cm.muState.Lock()
+		j.deferred = false
+		cm.muState.Unlock()
+
+		if run {
+			_ = fn(ctx)
+		}
+	}()
+}
+
+// newWindow parses HH:MM bounds and returns new window.
+func newWindow(from, to string, loc *time.Location) (window, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+
+	f, err := time.Parse("15:04", from)
+	if err != nil {
+		return window{}, fmt.Errorf("invalid window start=%q: %w", from, err)
+	}
+
+	t, err := time.Parse("15:04", to)
+	if err != nil {
+		return window{}, fmt.Errorf("invalid window end=%q: %w", to, err)
+	}
+
+	w := window{
+		from: f.Hour()*60 + f.Minute(),
+		to:   t.Hour()*60 + t.Minute(),
+		loc:  loc,
+	}
+	if w.from == w.to {
+		return window{}, fmt.Errorf("empty window %s", w)
+	}
+
+	return w, nil
+}
+
+// String returns window as "09:00-18:00 Location".
+func (w window) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d %s", w.from/60, w.from%60, w.to/60, w.to%60, w.loc)
+}
+
+// contains checks wall-clock time of t in window location.
+func (w window) contains(t time.Time) bool {
+	t = t.In(w.loc)
+	m := t.Hour()*60 + t.Minute()
+	if w.from < w.to {
+		return m >= w.from && m < w.to
+	}
+
+	return m >= w.from || m < w.to
+}
+
+// opensAt returns next window start after t or zero time if t is inside the window.
+func (w window) opensAt(t time.Time) time.Time {
+	if w.contains(t) {
+		return time.Time{}
+	}
+
+	t = t.In(w.loc)
+	y, m, d := t.Date()
+	next := time.Date(y, m, d, w.from/60, w.from%60, 0, 0, w.loc)
+	if !next.After(t) {
+		// use calendar day instead of 24h: day length differs on DST transitions
+		next = time.Date(y, m, d+1, w.from/60, w.from%60, 0, 0, w.loc)
+	}
+
+	return next
+}
+
+// middleware skips runs outside the window.
+func (w window) middleware() MiddlewareFunc {
+	return func(next Func) Func {
+		return func(ctx context.Context) error {
+			if now := clockFromContext(ctx).Now(); !w.contains(now) {
+				return newSkipError(SkipWindow, "outside window %s, paused until %s", w, w.opensAt(now).Format("15:04"))
+			}
+
+			return next(ctx)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWindow(t *testing.T) {
+	Convey("Test execution window", t, func() {
+		loc, err := time.LoadLocation("Europe/Berlin")
+		So(err, ShouldBeNil)
+
+		Convey("Test day window", func() {
+			w, err := newWindow("09:00", "18:00", loc)
+			So(err, ShouldBeNil)
+			So(w.String(), ShouldEqual, "09:00-18:00 Europe/Berlin")
+
+			So(w.contains(time.Date(2025, 3, 10, 9, 0, 0, 0, loc)), ShouldBeTrue)
+			So(w.contains(time.Date(2025, 3, 10, 17, 59, 0, 0, loc)), ShouldBeTrue)
+			So(w.contains(time.Date(2025, 3, 10, 18, 0, 0, 0, loc)), ShouldBeFalse)
+			So(w.contains(time.Date(2025, 3, 10, 8, 0, 0, 0, loc)), ShouldBeFalse)
+
+			So(w.opensAt(time.Date(2025, 3, 10, 12, 0, 0, 0, loc)).IsZero(), ShouldBeTrue)
+			So(w.opensAt(time.Date(2025, 3, 10, 7, 0, 0, 0, loc)), ShouldEqual, time.Date(2025, 3, 10, 9, 0, 0, 0, loc))
+			So(w.opensAt(time.Date(2025, 3, 10, 19, 0, 0, 0, loc)), ShouldEqual, time.Date(2025, 3, 11, 9, 0, 0, 0, loc))
+		})
+
+		Convey("Test overnight window", func() {
+			w, err := newWindow("22:00", "06:00", loc)
+			So(err, ShouldBeNil)
+
+			So(w.contains(time.Date(2025, 3, 10, 23, 0, 0, 0, loc)), ShouldBeTrue)
+			So(w.contains(time.Date(2025, 3, 10, 5, 0, 0, 0, loc)), ShouldBeTrue)
+			So(w.contains(time.Date(2025, 3, 10, 12, 0, 0, 0, loc)), ShouldBeFalse)
+		})
+
+		Convey("Test DST transition", func() {
+			// 2025-03-30 02:00 -> 03:00 in Europe/Berlin
+			w, err := newWindow("01:00", "04:00", loc)
+			So(err, ShouldBeNil)
+
+			start := time.Date(2025, 3, 30, 1, 0, 0, 0, loc)
+			So(w.contains(start.Add(2*time.Hour)), ShouldBeFalse) // 04:00 wall-clock, window is 2h long
+			So(w.contains(start.Add(time.Hour+30*time.Minute)), ShouldBeTrue)
+
+			// next opening is a calendar day later, not 24h
+			next := w.opensAt(time.Date(2025, 3, 29, 12, 0, 0, 0, loc))
+			So(next, ShouldEqual, time.Date(2025, 3, 30, 1, 0, 0, 0, loc))
+			next = w.opensAt(time.Date(2025, 3, 30, 12, 0, 0, 0, loc))
+			So(next, ShouldEqual, time.Date(2025, 3, 31, 1, 0, 0, 0, loc))
+		})
+
+		Convey("Test invalid window", func() {
+			m := NewManager()
+			m.AddFunc("f1", "0 0 * * *", newCronFunc("f1"), OnlyBetween("9am", "18:00", nil))
+			name, err := m.validateJobs()
+			So(err, ShouldNotBeNil)
+			So(name, ShouldEqual, "f1")
+		})
+	})
+}
+
+func TestMaintenanceWindow(t *testing.T) {
+	Convey("Test maintenance window", t, func() {
+		clock := &testClock{now: time.Date(2025, 3, 10, 11, 59, 30, 0, time.UTC)}
+
+		runs := map[string]int{}
+		counter := func(name string) Func {
+			return func(context.Context) error {
+				runs[name]++
+				return nil
+			}
+		}
+
+		m := NewManager(WithClock(clock), WithManualTicker(), MaintenanceWindow("01:00", "05:00", time.UTC))
+		m.AddFunc("regular", "* * * * *", counter("regular"))
+		m.AddMaintenanceFunc("vacuum", "* * * * *", counter("vacuum"))
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		clock.Add(time.Minute)
+		err := m.Tick(t.Context(), clock.Now())
+		So(errors.Is(err, ErrSkipped), ShouldBeTrue)
+		So(runs, ShouldResemble, map[string]int{"regular": 1})
+
+		st := m.State()
+		So(st[0].Window, ShouldBeEmpty)
+		So(st[1].LastState, ShouldEqual, "skipped")
+		So(st[1].SkipReason, ShouldEqual, "outside maintenance window 01:00-05:00 UTC")
+		So(st[1].Window, ShouldEqual, "01:00-05:00 UTC")
+		So(st[1].WindowOpensAt, ShouldEqual, time.Date(2025, 3, 11, 1, 0, 0, 0, time.UTC))
+
+		// manual runs are not affected
+		So(m.ManualRun(t.Context(), "vacuum"), ShouldBeNil)
+		So(runs["vacuum"], ShouldEqual, 1)
+
+		Convey("Test invalid window", func() {
+			m := NewManager(MaintenanceWindow("1am", "05:00", nil))
+			So(m.Run(t.Context()), ShouldNotBeNil)
+		})
+	})
+
+	Convey("Test deferred maintenance run", t, func() {
+		clock := &testClock{now: time.Date(2025, 3, 10, 23, 59, 30, 0, time.UTC), after: make(chan time.Duration)}
+		done := make(chan struct{}, 1)
+		m := NewManager(WithClock(clock), WithManualTicker(), MaintenanceWindow("01:00", "05:00", time.UTC), WithMaintenanceDefer())
+		m.AddMaintenanceFunc("vacuum", "0 * * * *", func(context.Context) error {
+			done <- struct{}{}
+			return nil
+		})
+		So(m.Run(t.Context()), ShouldBeNil)
+
+		clock.Add(time.Minute)
+		tick := make(chan error)
+		go func() { tick <- m.Tick(t.Context(), clock.Now()) }()
+		So(<-clock.after, ShouldEqual, 59*time.Minute+30*time.Second)
+		So(errors.Is(<-tick, ErrSkipped), ShouldBeTrue)
+		So(m.State()[0].SkipReason, ShouldEqual, "outside maintenance window 01:00-05:00 UTC, deferred to 01:00")
+
+		Convey("Test deferred run starts at window start by manager clock", func() {
+			clock.Add(59*time.Minute + 30*time.Second)
+			<-done
+		})
+
+		Convey("Test deferred run is dropped on reset", func() {
+			m.Reset()
+			clock.Add(time.Hour)
+			So(done, ShouldBeEmpty)
+		})
+	})
+}